@@ -1,9 +1,11 @@
 package main
 
 import (
+	"log/slog"
+	"os"
+
 	"github.com/SkyMack/thumbnailer/internal/clibase"
-	"github.com/SkyMack/thumbnailer/internal/generator"
-	log "github.com/sirupsen/logrus"
+	"github.com/SkyMack/thumbnailer/pkg/generator"
 )
 
 const (
@@ -15,13 +17,12 @@ func main() {
 	rootCmd := clibase.New(appName, appDescription)
 
 	generator.AddCmdGeneratePng(rootCmd)
+	generator.AddCmdGenerateJpeg(rootCmd)
+	generator.AddCmdGenerateGif(rootCmd)
+	generator.AddCmdGenerateBatch(rootCmd)
 
 	if err := rootCmd.Execute(); err != nil {
-		log.WithFields(
-			log.Fields{
-				"app.name": appName,
-				"error":    err.Error(),
-			},
-		).Fatal("application exited with an error")
+		slog.Default().Error("application exited with an error", "app.name", appName, "error", err.Error())
+		os.Exit(1)
 	}
 }
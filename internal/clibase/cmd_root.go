@@ -1,10 +1,11 @@
 package clibase
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -19,6 +20,8 @@ func AddRootFlags(flags *pflag.FlagSet) {
 	rootFlags := &pflag.FlagSet{}
 
 	addLogFlags(rootFlags)
+	addConfigFlags(rootFlags)
+	addOutputFlags(rootFlags)
 	flags.AddFlagSet(rootFlags)
 }
 
@@ -43,7 +46,6 @@ func NewFromRoot(rootCmd *cobra.Command) *cobra.Command {
 		oldPersistPreRunE := rootCmd.PersistentPreRunE
 		rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 			if err := oldPersistPreRunE(cmd, args); err != nil {
-				// Logging shit
 				return err
 			}
 			return rootPersistentPreRunE(cmd, args)
@@ -52,45 +54,76 @@ func NewFromRoot(rootCmd *cobra.Command) *cobra.Command {
 		persistentPreRunE = rootPersistentPreRunE
 	}
 
+	var persistentPostRunE func(*cobra.Command, []string) error
+	if rootCmd.PersistentPostRunE != nil {
+		oldPersistPostRunE := rootCmd.PersistentPostRunE
+		rootCmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+			if err := rootPersistentPostRunE(cmd, args); err != nil {
+				return err
+			}
+			return oldPersistPostRunE(cmd, args)
+		}
+	} else {
+		persistentPostRunE = rootPersistentPostRunE
+	}
+
 	rootCmd.PersistentPreRunE = persistentPreRunE
+	rootCmd.PersistentPostRunE = persistentPostRunE
 	AddRootFlags(rootCmd.PersistentFlags())
 	addVersionCmd(rootCmd)
 	return rootCmd
 }
 
+type logFileCloserCtxKey struct{}
+
 func rootPersistentPreRunE(cmd *cobra.Command, args []string) error {
 	flags := cmd.Flags()
+
+	if err := bindViper(cmd, cmd.Root().Name()); err != nil {
+		return err
+	}
+
 	logFormat, err := flags.GetString(logFlagFormatName)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"flag.name": logFlagFormatName,
-			"error":     err,
-		}).Error(ErrorFlagCannotRetrieve.Error())
-		return err
+		return fmt.Errorf("%w: %s: %s", ErrorFlagCannotRetrieve, logFlagFormatName, err)
 	}
 	logLevel, err := flags.GetString(logFlagLevelName)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"flag.name": logFlagLevelName,
-			"error":     err,
-		}).Error(ErrorFlagCannotRetrieve.Error())
+		return fmt.Errorf("%w: %s: %s", ErrorFlagCannotRetrieve, logFlagLevelName, err)
+	}
+	logFile, err := flags.GetString(logFlagFileName)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrorFlagCannotRetrieve, logFlagFileName, err)
+	}
+
+	logger, closeLogFile, err := configureLogging(logFormat, logLevel, logFile)
+	if err != nil {
 		return err
 	}
 
-	checkCobraFlags(flags)
+	checkCobraFlags(logger, flags)
 
-	return configureLogging(logFormat, logLevel)
+	ctx := ContextWithLogger(cmd.Context(), logger)
+	ctx = context.WithValue(ctx, logFileCloserCtxKey{}, closeLogFile)
+	cmd.SetContext(ctx)
+
+	return nil
 }
 
-func checkCobraFlags(flags *pflag.FlagSet) {
+func rootPersistentPostRunE(cmd *cobra.Command, _ []string) error {
+	closeLogFile, ok := cmd.Context().Value(logFileCloserCtxKey{}).(func() error)
+	if !ok {
+		return nil
+	}
+	return closeLogFile()
+}
+
+func checkCobraFlags(logger *slog.Logger, flags *pflag.FlagSet) {
 	// Warn if CLI flags don't follow style conventions
 	flags.VisitAll(func(flag *pflag.Flag) {
-		l := log.WithField("flag.name", flag.Name)
-		l.Tracef("checking flag for style")
-
 		if strings.Index(flag.Name, "_") > 0 {
 			// We don't use --foo_bar, we use --foo-bar.
-			l.WithField("violation", "flag names must use hyphen not underscore").Warnf("invalid flag name")
+			logger.Warn("invalid flag name: flag names must use hyphen not underscore", "flag.name", flag.Name)
 		}
 	})
 }
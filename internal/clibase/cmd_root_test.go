@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -100,4 +102,53 @@ func TestRootCmd(t *testing.T) {
 		err := cmd.Execute()
 		assert.Error(t, err)
 	})
+
+	t.Run("Config file sets a flag not given on the command line", func(t *testing.T) {
+		cfgFile := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(cfgFile, []byte(fmt.Sprintf("%s: json\n", logFlagFormatName)), 0o644))
+
+		cmd := New(name, description)
+		cmd.SetArgs([]string{"version", "--config", cfgFile})
+		outBuf := bytes.NewBufferString("")
+		cmd.SetOut(outBuf)
+		assert.NoError(t, cmd.Execute())
+		assert.Equal(t, logJSONFormatName, cmd.Flags().Lookup(logFlagFormatName).Value.String())
+	})
+
+	t.Run("Env var overrides config file", func(t *testing.T) {
+		cfgFile := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(cfgFile, []byte(fmt.Sprintf("%s: json\n", logFlagFormatName)), 0o644))
+		t.Setenv("COMMAND_NAME_LOG_FORMAT", logTextFormatName)
+
+		cmd := New(name, description)
+		cmd.SetArgs([]string{"version", "--config", cfgFile})
+		outBuf := bytes.NewBufferString("")
+		cmd.SetOut(outBuf)
+		assert.NoError(t, cmd.Execute())
+		assert.Equal(t, logTextFormatName, cmd.Flags().Lookup(logFlagFormatName).Value.String())
+	})
+
+	t.Run("Bare LOG_FORMAT env var sets the flag's default", func(t *testing.T) {
+		t.Setenv("LOG_FORMAT", logJSONFormatName)
+
+		cmd := New(name, description)
+		cmd.SetArgs([]string{"version"})
+		outBuf := bytes.NewBufferString("")
+		cmd.SetOut(outBuf)
+		assert.NoError(t, cmd.Execute())
+		assert.Equal(t, logJSONFormatName, cmd.Flags().Lookup(logFlagFormatName).Value.String())
+	})
+
+	t.Run("Explicit flag overrides env var and config file", func(t *testing.T) {
+		cfgFile := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(cfgFile, []byte(fmt.Sprintf("%s: json\n", logFlagFormatName)), 0o644))
+		t.Setenv("COMMAND_NAME_LOG_FORMAT", logJSONFormatName)
+
+		cmd := New(name, description)
+		cmd.SetArgs([]string{"version", "--config", cfgFile, fmt.Sprintf("--%s", logFlagFormatName), logTextFormatName})
+		outBuf := bytes.NewBufferString("")
+		cmd.SetOut(outBuf)
+		assert.NoError(t, cmd.Execute())
+		assert.Equal(t, logTextFormatName, cmd.Flags().Lookup(logFlagFormatName).Value.String())
+	})
 }
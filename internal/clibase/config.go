@@ -0,0 +1,59 @@
+package clibase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	configFlagName = "config"
+)
+
+func addConfigFlags(flags *pflag.FlagSet) {
+	configFlags := &pflag.FlagSet{}
+	configFlags.String(configFlagName, "", "Path to a YAML/TOML/JSON config file holding flag values")
+	flags.AddFlagSet(configFlags)
+}
+
+// bindViper resolves every flag on cmd against the environment and, if --config was given, a
+// config file, following the precedence explicit flag > env var > config file > flag default.
+// Resolved values are written back onto the flags themselves so the rest of the app can keep
+// reading configuration the normal pflag way.
+func bindViper(cmd *cobra.Command, envPrefix string) error {
+	flags := cmd.Flags()
+
+	v := viper.New()
+	v.SetEnvPrefix(strings.ToUpper(strings.ReplaceAll(envPrefix, "-", "_")))
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+
+	if err := v.BindPFlags(flags); err != nil {
+		return err
+	}
+
+	configFile, err := flags.GetString(configFlagName)
+	if err != nil {
+		return err
+	}
+	if configFile != "" {
+		v.SetConfigFile(configFile)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("unable to read config file %s: %w", configFile, err)
+		}
+	}
+
+	var setErr error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if setErr != nil || flag.Name == configFlagName || flag.Changed || !v.IsSet(flag.Name) {
+			return
+		}
+		if err := flags.Set(flag.Name, fmt.Sprintf("%v", v.Get(flag.Name))); err != nil {
+			setErr = fmt.Errorf("unable to apply resolved value for flag %q: %w", flag.Name, err)
+		}
+	})
+	return setErr
+}
@@ -0,0 +1,35 @@
+// Package flags holds small pflag.Value implementations shared across clibase and its consumers.
+package flags
+
+import "fmt"
+
+// Output is the format in which a command renders its results.
+type Output string
+
+const (
+	// OutputText renders results as human-readable text (the default).
+	OutputText Output = "text"
+	// OutputJSON renders results as a single JSON document.
+	OutputJSON Output = "json"
+)
+
+// String implements pflag.Value.
+func (o *Output) String() string {
+	return string(*o)
+}
+
+// Set implements pflag.Value.
+func (o *Output) Set(s string) error {
+	switch Output(s) {
+	case OutputText, OutputJSON:
+		*o = Output(s)
+		return nil
+	default:
+		return fmt.Errorf("accepted arguments are %s and %s", OutputText, OutputJSON)
+	}
+}
+
+// Type implements pflag.Value.
+func (o *Output) Type() string {
+	return "output"
+}
@@ -1,30 +1,33 @@
 package clibase
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
 
-	log "github.com/sirupsen/logrus"
-	"github.com/sirupsen/logrus/hooks/writer"
 	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 const (
 	logDefaultLevel   = "info"
+	logFlagFileName   = "log-file"
 	logFlagFormatName = "log-format"
 	logFlagLevelName  = "log-level"
 	logTextFormatName = "text"
 	logJSONFormatName = "json"
+
+	logFileStdout = "stdout"
+	logFileStderr = "stderr"
 )
 
 var (
-	logFormats = map[string]log.Formatter{
-		logJSONFormatName: &log.JSONFormatter{},
-		logTextFormatName: &log.TextFormatter{},
-	}
 	logDefaultFormat = logTextFormatName
+	logDefaultFile   = logFileStderr
+	logFormats       = []string{logJSONFormatName, logTextFormatName}
 
 	// ErrorLogInitFailure is the error logged when the initial log configuration setup fails
 	ErrorLogInitFailure = fmt.Errorf("failure during logging init")
@@ -34,87 +37,121 @@ var (
 	ErrorLogUnknownFormat = fmt.Errorf("unknown log format specified")
 )
 
-func init() {
-	// Set the initial logger configuration (used for any messages logged before flags can change the config)
-	if err := configureLogging(getLogSettings()); err != nil {
-		log.Error(ErrorLogInitFailure.Error())
-	}
+type loggerCtxKey struct{}
 
-	log.SetOutput(io.Discard) // Send all logs to nowhere by default
-	log.AddHook(&writer.Hook{ // Send logs with level higher than warning to stderr
-		Writer: os.Stderr,
-		LogLevels: []log.Level{
-			log.PanicLevel,
-			log.FatalLevel,
-			log.ErrorLevel,
-			log.WarnLevel,
-		},
-	})
-	log.AddHook(&writer.Hook{ // Send info, debug, and trace logs to stdout
-		Writer: os.Stdout,
-		LogLevels: []log.Level{
-			log.InfoLevel,
-			log.DebugLevel,
-			log.TraceLevel,
-		},
-	})
+// ContextWithLogger returns a copy of ctx carrying logger, retrievable with LoggerFromContext.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, logger)
 }
 
+// LoggerFromContext returns the *slog.Logger attached to ctx by a prior ContextWithLogger call, or
+// slog.Default() if none is present.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerCtxKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// addLogFlags registers the log flags with getLogSettings's bare LOG_FORMAT/LOG_LEVEL/LOG_FILE env
+// vars as their defaults, so those env vars keep working standalone - not just via bindViper's
+// app-name-prefixed (e.g. THUMBNAILER_LOG_LEVEL) binding, which only takes effect once a flag has
+// been registered. An explicit flag or prefixed env var both still take precedence, since bindViper
+// only overwrites a flag's value when the flag itself was left unchanged.
 func addLogFlags(flags *pflag.FlagSet) {
+	defaultFormat, defaultLevel, defaultFile := getLogSettings()
+
 	logFlags := &pflag.FlagSet{}
 
-	formats := make([]string, 0, len(logFormats))
-	for k := range logFormats {
-		formats = append(formats, k)
-	}
-	logFlags.String(logFlagFormatName, logDefaultFormat, fmt.Sprintf("The log format (valid values are: %s)", strings.Join(formats, ", ")))
-	logFlags.String(logFlagLevelName, logDefaultLevel, "The log level (trace, debug, info, warn, err, fatal)")
+	logFlags.String(logFlagFormatName, defaultFormat, fmt.Sprintf("The log format (valid values are: %s)", strings.Join(logFormats, ", ")))
+	logFlags.String(logFlagLevelName, defaultLevel, "The log level (debug, info, warn, error)")
+	logFlags.String(logFlagFileName, defaultFile, fmt.Sprintf("Where logs are written (%q, %q, or a file path)", logFileStdout, logFileStderr))
 
 	flags.AddFlagSet(logFlags)
 }
 
-func getLogSettings() (logFormat, logLevel string) {
-	level, isDefined := os.LookupEnv("LOG_LEVEL")
+// getLogSettings resolves the bare LOG_FORMAT/LOG_LEVEL/LOG_FILE env vars, falling back to this
+// package's defaults for whichever aren't set.
+func getLogSettings() (logFormat, logLevel, logFile string) {
+	logFormat, isDefined := os.LookupEnv("LOG_FORMAT")
+	if !isDefined {
+		logFormat = logDefaultFormat
+	}
+	logLevel, isDefined = os.LookupEnv("LOG_LEVEL")
 	if !isDefined {
-		level = logDefaultLevel
+		logLevel = logDefaultLevel
 	}
-	format, isDefined := os.LookupEnv("LOG_FORMAT")
+	logFile, isDefined = os.LookupEnv("LOG_FILE")
 	if !isDefined {
-		format = logDefaultFormat
+		logFile = logDefaultFile
 	}
-	return format, level
+	return
 }
 
-func configureLogging(logFormat, logLevel string) error {
-	log.WithFields(log.Fields{
-		"current.log.level":    log.GetLevel(),
-		"submitted.log.format": logFormat,
-		"submitted.log.level":  logLevel,
-	}).Trace("configureLogging START")
-
-	formatter, ok := logFormats[logFormat]
-	if !ok {
-		log.WithFields(log.Fields{
-			"submitted.log.format": logFormat,
-		}).Error(ErrorLogUnknownFormat.Error())
-		return ErrorLogUnknownFormat
+func parseLogLevel(logLevel string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return level, ErrorLogLevelParse
 	}
-	log.SetFormatter(formatter)
+	return level, nil
+}
 
-	logLevelParsed, err := log.ParseLevel(logLevel)
+// openLogFile resolves the literals "stdout"/"stderr" to the corresponding standard stream, or
+// opens logFile as a path (creating/appending to it). The returned closer is a no-op for the
+// standard streams, since those should never be closed out from under the process.
+func openLogFile(logFile string) (io.Writer, func() error, error) {
+	switch logFile {
+	case logFileStdout:
+		return os.Stdout, func() error { return nil }, nil
+	case logFileStderr, "":
+		return os.Stderr, func() error { return nil }, nil
+	default:
+		fh, err := os.OpenFile(logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fh, fh.Close, nil
+	}
+}
+
+// newHandler builds the slog.Handler for logFormat writing to w. The "text" format renders as a
+// colorized, human-friendly console line when w is an interactive terminal, falling back to
+// slog.TextHandler's plain key=value output otherwise.
+func newHandler(logFormat string, level slog.Level, w io.Writer) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch logFormat {
+	case logJSONFormatName:
+		return slog.NewJSONHandler(w, opts), nil
+	case logTextFormatName:
+		if fh, ok := w.(*os.File); ok && term.IsTerminal(int(fh.Fd())) {
+			return newFriendlyHandler(fh, opts), nil
+		}
+		return slog.NewTextHandler(w, opts), nil
+	default:
+		return nil, ErrorLogUnknownFormat
+	}
+}
+
+// configureLogging builds a *slog.Logger for the given format/level, writing to the destination
+// described by logFile ("stdout", "stderr", or a file path). The returned close func flushes and
+// closes the destination, if it owns one, and should be deferred/called on command exit.
+func configureLogging(logFormat, logLevel, logFile string) (*slog.Logger, func() error, error) {
+	level, err := parseLogLevel(logLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w, closeFn, err := openLogFile(logFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler, err := newHandler(logFormat, level, w)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"error":               err,
-			"submitted.log.level": logLevel,
-		}).Error(ErrorLogLevelParse.Error())
-		return err
+		closeFn()
+		return nil, nil, err
 	}
-	log.SetLevel(logLevelParsed)
-
-	log.WithFields(log.Fields{
-		"current.log.level":    log.GetLevel(),
-		"submitted.log.format": logFormat,
-		"submitted.log.level":  logLevel,
-	}).Trace("configureLogging END")
-	return nil
+
+	return slog.New(handler), closeFn, nil
 }
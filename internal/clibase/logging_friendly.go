@@ -0,0 +1,90 @@
+package clibase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// ansi color codes used to highlight the level of a friendly log line
+const (
+	ansiReset  = "\033[0m"
+	ansiGray   = "\033[90m"
+	ansiBlue   = "\033[34m"
+	ansiYellow = "\033[33m"
+	ansiRed    = "\033[31m"
+)
+
+// friendlyHandler is a slog.Handler meant for interactive terminal sessions: it renders a
+// colorized level, the message, and any attrs as trailing key=value pairs, skipping the timestamp
+// that would otherwise clutter output a human is watching scroll by in real time.
+type friendlyHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	opts  *slog.HandlerOptions
+	attrs []slog.Attr
+}
+
+func newFriendlyHandler(w io.Writer, opts *slog.HandlerOptions) *friendlyHandler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+	return &friendlyHandler{mu: &sync.Mutex{}, w: w, opts: opts}
+}
+
+func (h *friendlyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func levelColor(level slog.Level) (string, string) {
+	switch {
+	case level >= slog.LevelError:
+		return ansiRed, "ERROR"
+	case level >= slog.LevelWarn:
+		return ansiYellow, "WARN"
+	case level >= slog.LevelInfo:
+		return ansiBlue, "INFO"
+	default:
+		return ansiGray, "DEBUG"
+	}
+}
+
+func (h *friendlyHandler) Handle(_ context.Context, r slog.Record) error {
+	color, levelName := levelColor(r.Level)
+
+	line := fmt.Sprintf("%s%-5s%s %s", color, levelName, ansiReset, r.Message)
+
+	for _, attr := range h.attrs {
+		line += fmt.Sprintf(" %s%s=%v", ansiGray, attr.Key, attr.Value)
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		line += fmt.Sprintf(" %s%s=%v", ansiGray, attr.Key, attr.Value)
+		return true
+	})
+	line += ansiReset + "\n"
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line)
+	return err
+}
+
+func (h *friendlyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &friendlyHandler{
+		mu:    h.mu,
+		w:     h.w,
+		opts:  h.opts,
+		attrs: append(append([]slog.Attr{}, h.attrs...), attrs...),
+	}
+}
+
+func (h *friendlyHandler) WithGroup(_ string) slog.Handler {
+	// Groups aren't rendered specially in friendly output; attrs are flattened.
+	return h
+}
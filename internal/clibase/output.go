@@ -0,0 +1,28 @@
+package clibase
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/SkyMack/thumbnailer/internal/clibase/flags"
+)
+
+const (
+	outputFlagName = "output-format"
+)
+
+func addOutputFlags(flagSet *pflag.FlagSet) {
+	output := flags.OutputText
+	flagSet.Var(&output, outputFlagName, "Output format. One of: text|json")
+}
+
+// OutputFormat returns the resolved --output-format value for cmd, defaulting to flags.OutputText
+// if the flag isn't present (e.g. when called against a command that didn't go through
+// NewFromRoot).
+func OutputFormat(cmd *cobra.Command) flags.Output {
+	flag := cmd.Flags().Lookup(outputFlagName)
+	if flag == nil {
+		return flags.OutputText
+	}
+	return flags.Output(flag.Value.String())
+}
@@ -0,0 +1,308 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync"
+	"text/template"
+
+	"github.com/SkyMack/imgutils"
+	"github.com/SkyMack/thumbnailer/internal/clibase"
+	"github.com/golang/freetype/truetype"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	flagNameBatchJobsFile    = "jobs-file"
+	flagNameBatchConcurrency = "jobs-concurrency"
+
+	batchDefaultFontFilePath    = "assets/fonts/tahomabd.ttf"
+	batchDefaultFontColor       = "000000"
+	batchDefaultFontBorderColor = "FFFFFF"
+	batchDefaultFontBorderWidth = 2
+	batchDefaultFontSize        = 30
+	batchDefaultSeqNumDigits    = 2
+	batchDefaultTextLayerHeight = 1080
+	batchDefaultTextLayerWidth  = 1920
+)
+
+// BatchJob describes a single thumbnail generation run within a BatchSpec. Any field left empty
+// falls back to the same default the generate-png flags use.
+type BatchJob struct {
+	BaseName        string  `yaml:"base_name"`
+	BgImagePath     string  `yaml:"bg_image_path"`
+	DestPath        string  `yaml:"output_dest"`
+	FontFilePath    string  `yaml:"font_file_path"`
+	FontColor       string  `yaml:"font_color"`
+	FontBorderColor string  `yaml:"font_border_color"`
+	FontBorderWidth int     `yaml:"font_border_width"`
+	FontSize        float64 `yaml:"font_size"`
+	TextTemplate    string  `yaml:"text_template"`
+	SeqStart        int     `yaml:"seq_start"`
+	SeqEnd          int     `yaml:"seq_end"`
+	SeqNumDigits    int     `yaml:"seq_num_digits"`
+}
+
+// BatchSpec is the top level document parsed from a --jobs-file.
+type BatchSpec struct {
+	Jobs []BatchJob `yaml:"jobs"`
+}
+
+// loadBatchSpec reads and parses the YAML job spec at fpath.
+func loadBatchSpec(fpath string) (*BatchSpec, error) {
+	data, err := os.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec BatchSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse jobs file %s: %w", fpath, err)
+	}
+	if len(spec.Jobs) == 0 {
+		return nil, fmt.Errorf("jobs file %s does not define any jobs", fpath)
+	}
+
+	return &spec, nil
+}
+
+// fontCache parses each font file at most once, so a batch of jobs sharing a font only pays the
+// parse cost a single time.
+type fontCache struct {
+	mu    sync.Mutex
+	fonts map[string]*truetype.Font
+}
+
+func newFontCache() *fontCache {
+	return &fontCache{fonts: map[string]*truetype.Font{}}
+}
+
+func (c *fontCache) get(fpath string) (*truetype.Font, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if f, ok := c.fonts[fpath]; ok {
+		return f, nil
+	}
+
+	fontBytes, err := os.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+	parsedFont, err := truetype.Parse(fontBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	c.fonts[fpath] = parsedFont
+	return parsedFont, nil
+}
+
+// runBatch runs every job in spec, using up to concurrency workers, and returns the combined
+// Thumbnail results of every job that succeeded. Jobs that fail do not stop the other jobs in the
+// batch; their errors are aggregated and returned alongside whatever thumbnails did get produced.
+func runBatch(ctx context.Context, logger *slog.Logger, spec *BatchSpec, concurrency int) ([]Thumbnail, error) {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	fonts := newFontCache()
+	glyphs := NewGlyphCache()
+	jobCh := make(chan BatchJob)
+	resultsCh := make(chan []Thumbnail, len(spec.Jobs))
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs error
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				results, err := runBatchJob(ctx, logger, fonts, glyphs, job)
+				if err != nil {
+					mu.Lock()
+					errs = multierror.Append(errs, fmt.Errorf("job %q: %w", job.BaseName, err))
+					mu.Unlock()
+				}
+				resultsCh <- results
+			}
+		}()
+	}
+
+dispatch:
+	for _, job := range spec.Jobs {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobCh <- job:
+		}
+	}
+	close(jobCh)
+	wg.Wait()
+	close(resultsCh)
+
+	var allResults []Thumbnail
+	for results := range resultsCh {
+		allResults = append(allResults, results...)
+	}
+
+	if ctx.Err() != nil {
+		return allResults, ctx.Err()
+	}
+	return allResults, errs
+}
+
+// runBatchJob builds a Generator for job, applying the same defaults the generate-png flags use
+// for any field job leaves empty, and runs it to completion. fonts and glyphs are shared across
+// every job in the batch, so a font is only parsed once and each distinct glyph tile is only
+// rendered once for the whole batch, not once per job.
+func runBatchJob(ctx context.Context, logger *slog.Logger, fonts *fontCache, glyphs *GlyphCache, job BatchJob) ([]Thumbnail, error) {
+	cfg, err := job.toConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.WithGlyphCache(glyphs)
+	if err := cfg.validateStatic(); err != nil {
+		return nil, err
+	}
+
+	font, err := fonts.get(cfg.fontFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	gen, err := newGenerator(logger, cfg, font)
+	if err != nil {
+		return nil, err
+	}
+	gen.templateJob = job
+
+	if job.TextTemplate != "" {
+		tmpl, err := template.New(job.BaseName).Parse(job.TextTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse text_template: %w", err)
+		}
+		gen.labelTemplate = tmpl
+	}
+
+	// Results() is returned alongside a Generate error too: Generate aggregates per-frame failures
+	// via multierror rather than aborting the job, so a job that fails partway through still has
+	// every successfully rendered (and already written to disk) thumbnail recorded, and those
+	// shouldn't be dropped from the batch's combined manifest just because the job as a whole
+	// reported an error.
+	genErr := gen.Generate(ctx)
+	return gen.Results(), genErr
+}
+
+// toConfig converts a BatchJob into a Config, substituting the same defaults the generate-png
+// flags use wherever job leaves a field at its zero value.
+func (j BatchJob) toConfig() (Config, error) {
+	fontFilePath := j.FontFilePath
+	if fontFilePath == "" {
+		fontFilePath = batchDefaultFontFilePath
+	}
+	fontColorStr := j.FontColor
+	if fontColorStr == "" {
+		fontColorStr = batchDefaultFontColor
+	}
+	fontBorderColorStr := j.FontBorderColor
+	if fontBorderColorStr == "" {
+		fontBorderColorStr = batchDefaultFontBorderColor
+	}
+	fontBorderWidth := j.FontBorderWidth
+	if fontBorderWidth == 0 {
+		fontBorderWidth = batchDefaultFontBorderWidth
+	}
+	fontSize := j.FontSize
+	if fontSize == 0 {
+		fontSize = batchDefaultFontSize
+	}
+	seqNumDigits := j.SeqNumDigits
+	if seqNumDigits == 0 {
+		seqNumDigits = batchDefaultSeqNumDigits
+	}
+
+	fontColor, err := imgutils.ParseHexColor(fontColorStr)
+	if err != nil {
+		return Config{}, err
+	}
+	fontBorderColor, err := imgutils.ParseHexColor(fontBorderColorStr)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		baseName:        j.BaseName,
+		destPath:        j.DestPath,
+		fontBorderColor: fontBorderColor,
+		fontBorderWidth: fontBorderWidth,
+		fontColor:       &image.Uniform{C: fontColor},
+		fontFilePath:    fontFilePath,
+		fontSize:        fontSize,
+		numDigits:       seqNumDigits,
+		numStart:        j.SeqStart,
+		numEnd:          j.SeqEnd,
+		textImgHeight:   batchDefaultTextLayerHeight,
+		textImgWidth:    batchDefaultTextLayerWidth,
+		static: ConfigStatic{
+			bgImageFilePath: j.BgImagePath,
+		},
+	}, nil
+}
+
+func addBatchFlags(flags *pflag.FlagSet) {
+	batchFlags := &pflag.FlagSet{}
+	batchFlags.String(flagNameBatchJobsFile, "", "Path to a YAML file describing the batch of jobs to run (required)")
+	batchFlags.Int(flagNameBatchConcurrency, 0, "Number of jobs to run concurrently (defaults to the number of CPUs)")
+
+	flags.AddFlagSet(batchFlags)
+}
+
+// AddCmdGenerateBatch adds the generate-batch subcommand to a cobra.Command
+func AddCmdGenerateBatch(parentCmd *cobra.Command) {
+	batchCmd := &cobra.Command{
+		Use:   "generate-batch",
+		Short: "generate PNG thumbnails for a batch of jobs described by a YAML file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+
+			jobsFile, err := flags.GetString(flagNameBatchJobsFile)
+			if err != nil {
+				return err
+			}
+			concurrency, err := flags.GetInt(flagNameBatchConcurrency)
+			if err != nil {
+				return err
+			}
+
+			spec, err := loadBatchSpec(jobsFile)
+			if err != nil {
+				return err
+			}
+
+			results, err := runBatch(cmd.Context(), clibase.LoggerFromContext(cmd.Context()), spec, concurrency)
+			if manifestErr := writeManifest(cmd.OutOrStdout(), clibase.OutputFormat(cmd), results); manifestErr != nil {
+				return manifestErr
+			}
+			return err
+		},
+	}
+	addBatchFlags(batchCmd.Flags())
+	if err := batchCmd.MarkFlagRequired(flagNameBatchJobsFile); err != nil {
+		slog.Default().Error("unable to mark required flags", "error", err)
+		os.Exit(1)
+	}
+
+	parentCmd.AddCommand(batchCmd)
+}
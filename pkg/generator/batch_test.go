@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"context"
+	"image"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestBgImage saves a blank background image to dir for use as a BatchJob's BgImagePath.
+func writeTestBgImage(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "bg.png")
+	_, err := encodeImage(slog.Default(), image.NewNRGBA(image.Rect(0, 0, 320, 180)), path, FormatPNG, EncodeOptions{})
+	require.NoError(t, err)
+	return path
+}
+
+// TestRunBatchJobPreservesResultsOnPartialFailure pins the fix where a job that fails partway
+// through (one frame's label template errors) used to discard every frame that rendered
+// successfully before the failure. The template here only dereferences a nonexistent field on
+// frame 2, so frames 1 and 3 render fine and frame 2 fails.
+func TestRunBatchJobPreservesResultsOnPartialFailure(t *testing.T) {
+	dir := t.TempDir()
+	job := BatchJob{
+		BaseName:     "partial",
+		BgImagePath:  writeTestBgImage(t, dir),
+		DestPath:     dir,
+		FontFilePath: filepath.Join("testdata", "fonts", "test.ttf"),
+		TextTemplate: "{{if eq .Index 2}}{{.Nope}}{{else}}{{.Padded}}{{end}}",
+		SeqStart:     1,
+		SeqEnd:       3,
+	}
+
+	results, err := runBatchJob(context.Background(), slog.Default(), newFontCache(), NewGlyphCache(), job)
+
+	require.Error(t, err, "frame 2's template should fail")
+	require.Len(t, results, 2, "frames 1 and 3 should still be reported instead of being discarded")
+	indexes := []int{results[0].Index, results[1].Index}
+	assert.ElementsMatch(t, []int{1, 3}, indexes)
+}
+
+// TestRunBatchPreservesResultsOnPartialFailure is the same scenario one level up, through
+// runBatch's worker pool, confirming the combined manifest still includes a failing job's
+// successfully rendered frames.
+func TestRunBatchPreservesResultsOnPartialFailure(t *testing.T) {
+	dir := t.TempDir()
+	spec := &BatchSpec{
+		Jobs: []BatchJob{
+			{
+				BaseName:     "partial",
+				BgImagePath:  writeTestBgImage(t, dir),
+				DestPath:     dir,
+				FontFilePath: filepath.Join("testdata", "fonts", "test.ttf"),
+				TextTemplate: "{{if eq .Index 2}}{{.Nope}}{{else}}{{.Padded}}{{end}}",
+				SeqStart:     1,
+				SeqEnd:       3,
+			},
+		},
+	}
+
+	results, err := runBatch(context.Background(), slog.Default(), spec, 1)
+
+	require.Error(t, err)
+	require.Len(t, results, 2, "the job's 2 successful frames should still be in the combined manifest")
+}
+
+// TestLabelTemplateTotalAndJob pins the documented request#chunk0-5 example template - "Episode
+// {{.Padded}} of {{.Total}}" - and also exercises {{.Job}} fields, both of which a labelTemplate
+// couldn't reference at all before labelTemplateData grew a Total and Job field.
+func TestLabelTemplateTotalAndJob(t *testing.T) {
+	dir := t.TempDir()
+	job := BatchJob{
+		BaseName:     "labeled",
+		BgImagePath:  writeTestBgImage(t, dir),
+		DestPath:     dir,
+		FontFilePath: filepath.Join("testdata", "fonts", "test.ttf"),
+		TextTemplate: "Episode {{.Padded}} of {{.Total}} ({{.Job.BaseName}})",
+		SeqStart:     1,
+		SeqEnd:       3,
+	}
+
+	results, err := runBatchJob(context.Background(), slog.Default(), newFontCache(), NewGlyphCache(), job)
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "Episode 01 of 3 (labeled)", results[0].Label)
+}
@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"image"
+	"io"
+	"strings"
+	"sync"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// knownDecoderFormats lists the formats importImg can decode via the standard image.Decode
+// fallback - the stdlib's own png/jpeg/gif, plus whatever the blank imports above have
+// registered - and is only used to make decode failures easier to diagnose.
+var knownDecoderFormats = []string{"png", "jpeg", "gif", "bmp", "tiff", "webp"}
+
+// ImageDecoder decodes a single image from r. It's the signature expected by RegisterDecoder.
+type ImageDecoder func(r io.Reader) (image.Image, error)
+
+var (
+	decoderRegistryMu sync.RWMutex
+	decoderRegistry   = map[string]ImageDecoder{}
+)
+
+// RegisterDecoder registers decoder as the handler for files with the given extension (matched
+// case-insensitively, with or without a leading dot). importImg consults the registry before
+// falling back to image.Decode, so callers can plug in proprietary or otherwise unsupported still
+// formats without forking this package.
+func RegisterDecoder(ext string, decoder ImageDecoder) {
+	ext = normalizeExt(ext)
+
+	decoderRegistryMu.Lock()
+	defer decoderRegistryMu.Unlock()
+	decoderRegistry[ext] = decoder
+}
+
+func lookupDecoder(ext string) (ImageDecoder, bool) {
+	decoderRegistryMu.RLock()
+	defer decoderRegistryMu.RUnlock()
+	d, ok := decoderRegistry[normalizeExt(ext)]
+	return d, ok
+}
+
+func normalizeExt(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
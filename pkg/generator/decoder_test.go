@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImportImgPrefersRegisteredDecoder confirms importImg consults the RegisterDecoder registry
+// before falling back to image.Decode: a file with a registered extension decodes via the
+// registered ImageDecoder even though its content isn't a real image in any format image.Decode
+// understands.
+func TestImportImgPrefersRegisteredDecoder(t *testing.T) {
+	want := color.NRGBA{R: 10, G: 20, B: 30, A: 255}
+	RegisterDecoder(".custom-test-format", func(r io.Reader) (image.Image, error) {
+		img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+		for x := 0; x < 2; x++ {
+			for y := 0; y < 2; y++ {
+				img.SetNRGBA(x, y, want)
+			}
+		}
+		return img, nil
+	})
+
+	path := filepath.Join(t.TempDir(), "still.custom-test-format")
+	require.NoError(t, os.WriteFile(path, []byte("not a real image"), 0o644))
+
+	got, err := importImg(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, want, got.NRGBAAt(0, 0))
+}
+
+// TestLookupDecoderNormalizesExt confirms RegisterDecoder/lookupDecoder match regardless of case
+// or a leading dot, since importImg looks extensions up via filepath.Ext (which includes the dot).
+func TestLookupDecoderNormalizesExt(t *testing.T) {
+	RegisterDecoder("CUSTOM-TEST-EXT", func(r io.Reader) (image.Image, error) {
+		return image.NewNRGBA(image.Rect(0, 0, 1, 1)), nil
+	})
+
+	_, ok := lookupDecoder(".custom-test-ext")
+	assert.True(t, ok, "a decoder registered without a leading dot, in a different case, should still be found")
+}
+
+// TestImportImgUnsupportedFormatError confirms a file image.Decode can't handle fails with an
+// error naming every format importImg supports, so the failure is easy to diagnose.
+func TestImportImgUnsupportedFormatError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "still.unsupported-test-ext")
+	require.NoError(t, os.WriteFile(path, []byte("not a real image"), 0o644))
+
+	_, err := importImg(path)
+
+	require.Error(t, err)
+	for _, format := range knownDecoderFormats {
+		assert.Contains(t, err.Error(), format)
+	}
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// ImageFormat identifies the container a Generator encodes its rendered thumbnails into.
+type ImageFormat string
+
+const (
+	FormatPNG  ImageFormat = "png"
+	FormatJPEG ImageFormat = "jpeg"
+	// FormatWebP decodes (see decoder.go) but cannot be encoded: golang.org/x/image/webp only
+	// implements a reader, and this project has no pure-Go WebP encoder to depend on. encodeImage
+	// rejects it outright, and no generate-webp subcommand is registered until one exists.
+	FormatWebP ImageFormat = "webp"
+)
+
+// extension returns the filename extension (without a leading dot) conventionally used for format.
+func (f ImageFormat) extension() string {
+	switch f {
+	case FormatJPEG:
+		return "jpg"
+	case FormatWebP:
+		return "webp"
+	default:
+		return "png"
+	}
+}
+
+// EncodeOptions carries the format-specific knobs encodeImage needs for formats that have them
+// (currently only JPEG's quality).
+type EncodeOptions struct {
+	JPEGQuality int
+}
+
+// encodeImage writes img to destFile in the given format and returns the hex-encoded SHA-256 of the
+// bytes written.
+func encodeImage(logger *slog.Logger, img image.Image, destFile string, format ImageFormat, opts EncodeOptions) (string, error) {
+	if format == FormatWebP {
+		// golang.org/x/image/webp only implements a decoder; there is no pure-Go WebP encoder this
+		// project can depend on, so rather than silently writing something else we fail clearly.
+		return "", fmt.Errorf("webp encoding is not supported: no pure-Go WebP encoder is available")
+	}
+
+	destFh, err := os.Create(destFile)
+	if err != nil {
+		return "", err
+	}
+	defer destFh.Close()
+
+	logger.Info("saving image file", "dst.path", destFile, "format", format)
+	hasher := sha256.New()
+	w := io.MultiWriter(destFh, hasher)
+
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(w, img, &jpeg.Options{Quality: opts.JPEGQuality})
+	default:
+		err = png.Encode(w, img)
+	}
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/SkyMack/thumbnailer/internal/clibase"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/image/draw"
+)
+
+const (
+	flagNameGifFrameDelay = "frame-delay"
+	flagNameGifLoopCount  = "loop-count"
+	flagNameGifNumColors  = "palette-colors"
+	flagNameGifDither     = "dither"
+)
+
+// GenerateGIF renders the Generator's static sequence and encodes it as a single animated GIF that
+// cycles through every frame, rather than exporting one file per frame. Only static baseImage
+// composition is supported - an animated GIF built from a unique baseImage per frame would need a
+// different source for every cycle, which isn't a meaningful "animation" in the same sense.
+func (g *Generator) GenerateGIF(ctx context.Context) (Thumbnail, error) {
+	if g.bgImage == nil {
+		return Thumbnail{}, fmt.Errorf("generate-gif only supports static baseImage composition")
+	}
+
+	frames, err := g.renderGIFFrames(ctx)
+	if err != nil {
+		return Thumbnail{}, err
+	}
+
+	anim := &gif.GIF{LoopCount: g.cfg.gifLoopCount}
+	for _, frame := range frames {
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, g.cfg.gifFrameDelay)
+	}
+
+	fileName := fmt.Sprintf("thumbnail_%s.gif", g.cfg.baseName)
+	filePath := filepath.Join(g.cfg.destPath, fileName)
+	destFh, err := os.Create(filePath)
+	if err != nil {
+		return Thumbnail{}, err
+	}
+	defer destFh.Close()
+
+	g.logger.Info("saving animated GIF file", "dst.path", filePath, "frames", len(frames))
+	hasher := sha256.New()
+	if err := gif.EncodeAll(io.MultiWriter(destFh, hasher), anim); err != nil {
+		return Thumbnail{}, err
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return Thumbnail{}, err
+	}
+
+	result := Thumbnail{
+		Index:  g.cfg.numEnd,
+		Path:   absPath,
+		SHA256: hex.EncodeToString(hasher.Sum(nil)),
+		Width:  frames[0].Bounds().Dx(),
+		Height: frames[0].Bounds().Dy(),
+		Label:  fmt.Sprintf("%d frames", len(frames)),
+	}
+	g.recordResult(result)
+	return result, nil
+}
+
+// renderGIFFrames renders every frame in the Generator's sequence, in parallel via runSequence,
+// and returns them as palette-quantized images ordered by sequence number.
+func (g *Generator) renderGIFFrames(ctx context.Context) ([]*image.Paletted, error) {
+	var framesMu sync.Mutex
+	frames := make(map[int]*image.Paletted, g.cfg.numEnd-g.cfg.numStart+1)
+
+	err := g.runSequence(ctx, func(seqNumber int) error {
+		thumb := &thumbnail{
+			baseImage: g.bgImage,
+			seqNumber: seqNumber,
+		}
+		if err := g.renderThumbnail(thumb); err != nil {
+			return err
+		}
+		frame := g.paletteFrame(scaleToFinal(thumb.image))
+
+		framesMu.Lock()
+		frames[seqNumber] = frame
+		framesMu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]*image.Paletted, 0, len(frames))
+	for i := g.cfg.numStart; i <= g.cfg.numEnd; i++ {
+		if frame, ok := frames[i]; ok {
+			ordered = append(ordered, frame)
+		}
+	}
+	return ordered, nil
+}
+
+// paletteFrame quantizes img down to the Generator's configured GIF palette, optionally applying
+// Floyd-Steinberg dithering.
+func (g *Generator) paletteFrame(img *image.NRGBA) *image.Paletted {
+	pal := palette.Plan9
+	if g.cfg.gifNumColors > 0 && g.cfg.gifNumColors < len(pal) {
+		pal = pal[:g.cfg.gifNumColors]
+	}
+
+	paletted := image.NewPaletted(img.Bounds(), pal)
+	drawer := draw.Drawer(draw.Src)
+	if g.cfg.gifDither {
+		drawer = draw.FloydSteinberg
+	}
+	drawer.Draw(paletted, img.Bounds(), img, image.Point{})
+	return paletted
+}
+
+func addGifFlags(flags *pflag.FlagSet) {
+	gifFlags := &pflag.FlagSet{}
+	gifFlags.Int(flagNameGifFrameDelay, 10, "Delay between frames, in hundredths of a second")
+	gifFlags.Int(flagNameGifLoopCount, 0, "Number of times the animation loops; 0 loops forever")
+	gifFlags.Int(flagNameGifNumColors, 216, "Number of colors in the GIF's palette (up to 256)")
+	gifFlags.Bool(flagNameGifDither, false, "Apply Floyd-Steinberg dithering when quantizing frames to the palette")
+
+	flags.AddFlagSet(gifFlags)
+}
+
+func (c *Config) setGifConfigFromFlags(flags *pflag.FlagSet) error {
+	frameDelay, err := flags.GetInt(flagNameGifFrameDelay)
+	if err != nil {
+		return err
+	}
+	loopCount, err := flags.GetInt(flagNameGifLoopCount)
+	if err != nil {
+		return err
+	}
+	numColors, err := flags.GetInt(flagNameGifNumColors)
+	if err != nil {
+		return err
+	}
+	dither, err := flags.GetBool(flagNameGifDither)
+	if err != nil {
+		return err
+	}
+
+	c.gifFrameDelay = frameDelay
+	c.gifLoopCount = loopCount
+	c.gifNumColors = numColors
+	c.gifDither = dither
+
+	return nil
+}
+
+// AddCmdGenerateGif adds the generate-gif subcommand to a cobra.Command. It only supports static
+// baseImage composition - see GenerateGIF.
+func AddCmdGenerateGif(parentCmd *cobra.Command) {
+	gifCmd := &cobra.Command{
+		Use:   "generate-gif",
+		Short: "generate a single animated GIF cycling through the thumbnail sequence",
+	}
+	addGeneratePersistentFlags(gifCmd.PersistentFlags())
+	addGifFlags(gifCmd.PersistentFlags())
+	addGenerateStaticFlags(gifCmd.PersistentFlags())
+	if err := markGenerateRequiredFlags(gifCmd); err != nil {
+		slog.Default().Error("unable to mark required flags", "error", err)
+		os.Exit(1)
+	}
+	gifCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		var cfg Config
+		if err := cfg.setPersistentConfigFromFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		if err := cfg.setStaticConfigFromFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		if err := cfg.setGifConfigFromFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		if err := cfg.validateStatic(); err != nil {
+			return err
+		}
+
+		gen, err := NewGenerator(cmd.Context(), cfg)
+		if err != nil {
+			return err
+		}
+
+		if _, err := gen.GenerateGIF(cmd.Context()); err != nil {
+			return err
+		}
+
+		return writeManifest(cmd.OutOrStdout(), clibase.OutputFormat(cmd), gen.Results())
+	}
+
+	parentCmd.AddCommand(gifCmd)
+}
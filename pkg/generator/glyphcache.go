@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/SkyMack/imgutils"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// glyphKey identifies a single pre-rendered glyph tile. Including font itself (rather than just
+// its file path) means a cache is automatically invalidated the moment any of these parameters
+// change - a new key simply never matches an existing tile, so stale entries are just left
+// unreachable rather than needing to be explicitly evicted. fontColor/fontBorderColor/
+// fontBorderWidth/fontBorderAlphaThresh have to be part of the key too, not just the font/size/
+// DPI/hinting: generate-batch shares one GlyphCache across every job in a run, and two jobs
+// sharing a font and size but using different colors or border widths would otherwise silently
+// reuse each other's cached (and wrongly colored/bordered) tile.
+type glyphKey struct {
+	r                     rune
+	font                  *truetype.Font
+	fontSize              float64
+	dpi                   float64
+	hinting               font.Hinting
+	fontColor             color.NRGBA
+	fontBorderColor       color.NRGBA
+	fontBorderWidth       int
+	fontBorderAlphaThresh uint8
+}
+
+// glyphTile is a single rune, already drawn and bordered, ready to be composited into a text
+// layer at an arbitrary position.
+type glyphTile struct {
+	img     *image.NRGBA
+	advance fixed.Int26_6
+	dotX    int
+	dotY    int
+}
+
+// GlyphCache stores pre-rendered, bordered NRGBA tiles for the runes drawn into thumbnail labels,
+// keyed by glyphKey (rune, font, fontSize, dpi, hinting, and the color/border settings used to
+// draw it). It's safe for concurrent use, so a single GlyphCache can be shared across every
+// Generator in a batch, even one mixing font colors or border settings across jobs - see
+// Config.WithGlyphCache.
+type GlyphCache struct {
+	mu    sync.Mutex
+	tiles map[glyphKey]*glyphTile
+}
+
+// NewGlyphCache returns an empty GlyphCache.
+func NewGlyphCache() *GlyphCache {
+	return &GlyphCache{tiles: map[glyphKey]*glyphTile{}}
+}
+
+// glyphTile returns the cached tile for r under g's current font configuration, rendering and
+// caching it first if this is the first time that combination has been seen.
+func (c *GlyphCache) glyphTile(g *Generator, r rune) (*glyphTile, error) {
+	key := glyphKey{
+		r:                     r,
+		font:                  g.font,
+		fontSize:              g.cfg.fontSize,
+		dpi:                   fontDPI,
+		hinting:               font.HintingFull,
+		fontColor:             uniformNRGBA(g.cfg.fontColor),
+		fontBorderColor:       g.cfg.fontBorderColor,
+		fontBorderWidth:       g.cfg.fontBorderWidth,
+		fontBorderAlphaThresh: g.cfg.fontBorderAlphaThresh,
+	}
+
+	c.mu.Lock()
+	tile, ok := c.tiles[key]
+	c.mu.Unlock()
+	if ok {
+		return tile, nil
+	}
+
+	tile, err := renderGlyphTile(g, r)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.tiles[key] = tile
+	c.mu.Unlock()
+	return tile, nil
+}
+
+// uniformNRGBA extracts u's underlying color as a comparable color.NRGBA, so glyphKey can key on
+// the actual color rather than the *image.Uniform pointer (two Configs with the same color would
+// otherwise never share a cache entry).
+func uniformNRGBA(u *image.Uniform) color.NRGBA {
+	return color.NRGBAModel.Convert(u.C).(color.NRGBA)
+}
+
+// renderGlyphTile draws and borders a single rune onto a scratch canvas sized just large enough
+// to hold it, mirroring the same border/blend passes renderThumbnail used to run per-string.
+func renderGlyphTile(g *Generator, r rune) (*glyphTile, error) {
+	cfg := g.cfg
+
+	face := truetype.NewFace(g.font, &truetype.Options{
+		Size:    cfg.fontSize,
+		DPI:     fontDPI,
+		Hinting: font.HintingFull,
+	})
+	advance, ok := face.GlyphAdvance(r)
+	if !ok {
+		return nil, fmt.Errorf("font does not contain a glyph for %q", r)
+	}
+
+	pad := cfg.fontBorderWidth + 4
+	baselineY := int(math.Ceil(cfg.fontSize*fontDPI/72)) + pad*2
+	dot := fixed.Point26_6{X: fixed.I(pad), Y: fixed.I(baselineY)}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, advance.Ceil()+pad*2, baselineY+pad*2))
+	drawer := &font.Drawer{Dst: canvas, Src: cfg.fontColor, Face: face, Dot: dot}
+
+	drawer.DrawString(string(r))
+	imgutils.AddBorders(canvas, cfg.fontBorderColor, cfg.fontBorderWidth, cfg.fontBorderAlphaThresh)
+	drawer.Dot = dot
+	drawer.DrawString(string(r))
+
+	borderColorSoft := cfg.fontBorderColor
+	borderColorSoft.A = 150
+	borderColorSofter := cfg.fontBorderColor
+	borderColorSofter.A = 65
+	imgutils.AddBorders(canvas, borderColorSoft, 1, cfg.fontBorderAlphaThresh)
+	imgutils.AddBorders(canvas, borderColorSofter, 1, 149)
+
+	return &glyphTile{img: canvas, advance: advance, dotX: pad, dotY: baselineY}, nil
+}
+
+// drawLabel composes text into dst by compositing cached glyph tiles side by side starting at
+// dot, advancing by each glyph's GlyphAdvance. Unlike drawing the whole string with a
+// font.Drawer, this pays the AddBorders blend passes once per distinct rune rather than once per
+// thumbnail.
+func (g *Generator) drawLabel(dst *image.NRGBA, text string, dot fixed.Point26_6) error {
+	x := dot.X
+	for _, r := range text {
+		tile, err := g.glyphCache.glyphTile(g, r)
+		if err != nil {
+			return err
+		}
+
+		destX := x.Round() - tile.dotX
+		destY := dot.Y.Round() - tile.dotY
+		destRect := image.Rect(destX, destY, destX+tile.img.Bounds().Dx(), destY+tile.img.Bounds().Dy())
+		draw.Draw(dst, destRect, tile.img, image.Point{}, draw.Over)
+
+		x += tile.advance
+	}
+	return nil
+}
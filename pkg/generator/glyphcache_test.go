@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniformNRGBA(t *testing.T) {
+	c := color.NRGBA{R: 10, G: 20, B: 30, A: 255}
+	assert.Equal(t, c, uniformNRGBA(&image.Uniform{C: c}))
+}
+
+func TestGlyphKeyDistinguishesColorAndBorder(t *testing.T) {
+	base := glyphKey{
+		r:               'A',
+		fontSize:        30,
+		dpi:             fontDPI,
+		fontColor:       color.NRGBA{A: 255},
+		fontBorderColor: color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		fontBorderWidth: 2,
+	}
+
+	differentColor := base
+	differentColor.fontColor = color.NRGBA{R: 255, A: 255}
+	assert.NotEqual(t, base, differentColor,
+		"two jobs sharing a font/size but using different font colors must not collide in the glyph cache")
+
+	differentBorderColor := base
+	differentBorderColor.fontBorderColor = color.NRGBA{A: 255}
+	assert.NotEqual(t, base, differentBorderColor,
+		"two jobs sharing a font/size but using different border colors must not collide in the glyph cache")
+
+	differentBorderWidth := base
+	differentBorderWidth.fontBorderWidth = 5
+	assert.NotEqual(t, base, differentBorderWidth,
+		"two jobs sharing a font/size but using different border widths must not collide in the glyph cache")
+
+	same := base
+	assert.Equal(t, base, same)
+}
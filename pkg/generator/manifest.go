@@ -0,0 +1,32 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/SkyMack/thumbnailer/internal/clibase/flags"
+)
+
+// manifest is the machine-readable description of a completed generation run.
+type manifest struct {
+	Thumbnails []Thumbnail `json:"thumbnails"`
+}
+
+// writeManifest renders the thumbnails produced by a Generator run to w, in either a tidy text
+// table or as a single JSON document, depending on format.
+func writeManifest(w io.Writer, format flags.Output, thumbnails []Thumbnail) error {
+	if format == flags.OutputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(manifest{Thumbnails: thumbnails})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tLABEL\tWIDTHxHEIGHT\tSHA256\tPATH")
+	for _, t := range thumbnails {
+		fmt.Fprintf(tw, "%d\t%s\t%dx%d\t%s\t%s\n", t.Index, t.Label, t.Width, t.Height, t.SHA256, t.Path)
+	}
+	return tw.Flush()
+}
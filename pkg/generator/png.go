@@ -0,0 +1,1023 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"log/slog"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/SkyMack/imgutils"
+	"github.com/SkyMack/thumbnailer/internal/clibase"
+	"github.com/golang/freetype/truetype"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	flagNameBaseName            = "base-name"
+	flagNameBgImage             = "bg-baseImage"
+	flagNameDestPath            = "output-dest"
+	flagNameFontBorderColor     = "font-border-color"
+	flagNameFontBorderWidth     = "font-border-width"
+	flagNameFontColor           = "font-color"
+	flagNameFontSize            = "font-size"
+	flagNameJpegQuality         = "quality"
+	flagNameParallelism         = "parallelism"
+	flagNameSeqEnd              = "seq-end"
+	flagNameSeqNumDigits        = "seq-num-digits"
+	flagNameSeqNumPosX          = "seq-num-pos-x"
+	flagNameSeqNumPosY          = "seq-num-pos-y"
+	flagNameSeqStart            = "seq-start"
+	flagNameStillFilenameExt    = "still-filename-ext"
+	flagNameStillFilenamePrefix = "still-filename-prefix"
+	flagNameStillSrcPath        = "still-src"
+	flagNameTextLayerHeight     = "text-layer-height"
+	flagNameTextLayerWidth      = "text-layer-width"
+	flagNameTitleOverlayPath    = "title-overlay-img"
+
+	fontBorderAlphaThreshold = "font-border-alpha-thresh"
+	fontDPI                  = 300
+
+	imageFinalHeight = 720
+	imageFinalWidth  = 1280
+)
+
+// debugEnabled reports whether DEBUG is set in the environment, enabling a Generator's debug-only
+// text layer dump (see Generator.debug). Checked once per Generator, in newGenerator, rather than
+// cached in a package-level global, so it isn't fixed for the whole process at init() time.
+func debugEnabled() bool {
+	_, ok := os.LookupEnv("DEBUG")
+	return ok
+}
+
+type thumbnail struct {
+	baseImage       *image.NRGBA
+	image           *image.NRGBA
+	paddedSeqNumber string
+	seqNumber       int
+	stillPath       string
+	titleImage      *image.NRGBA
+}
+
+// Config is used to store the configuration options for the thumbnail generator
+type Config struct {
+	baseName              string
+	destPath              string
+	fontBorderAlphaThresh uint8
+	fontBorderColor       color.NRGBA
+	fontBorderWidth       int
+	fontColor             *image.Uniform
+	fontFilePath          string
+	fontSize              float64
+	format                ImageFormat
+	glyphCache            *GlyphCache
+	jpegQuality           int
+	gifFrameDelay         int
+	gifLoopCount          int
+	gifNumColors          int
+	gifDither             bool
+	numDigits             int
+	numPosX               int
+	numPosY               int
+	numEnd                int
+	numStart              int
+	parallelism           int
+	textElements          []TextElement
+	textImgHeight         int
+	textImgWidth          int
+
+	dynamic ConfigDynamic
+	static  ConfigStatic
+}
+
+// ConfigStatic stores the configuration options related to generating static thumbnails
+type ConfigStatic struct {
+	bgImageFilePath string
+}
+
+// ConfigDynamic stores the configuration options related to generating dynamic thumbnails
+type ConfigDynamic struct {
+	stillFilenameExt    string
+	stillFilenamePrefix string
+	stillSourceDirPath  string
+	titleImageFilePath  string
+}
+
+// NewConfig returns a Config for a sequence running from seqStart to seqEnd, with the same
+// defaults the CLI flags use for everything else (30pt tahomabd.ttf, black text with a white
+// border, PNG output). Use the Config's With* methods to customize it further before passing it to
+// NewGenerator.
+func NewConfig(baseName, destPath string, seqStart, seqEnd int) Config {
+	return Config{
+		baseName:        baseName,
+		destPath:        destPath,
+		fontBorderColor: color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		fontBorderWidth: 2,
+		fontColor:       &image.Uniform{C: color.NRGBA{A: 255}},
+		fontFilePath:    filepath.Join("assets", "fonts", "tahomabd.ttf"),
+		fontSize:        30,
+		format:          FormatPNG,
+		numDigits:       2,
+		numStart:        seqStart,
+		numEnd:          seqEnd,
+		textImgHeight:   1080,
+		textImgWidth:    1920,
+	}
+}
+
+// WithFont sets the TrueType font file the sequence number label and any TextElements are
+// rendered with, and its point size.
+func (c Config) WithFont(fontFilePath string, fontSize float64) Config {
+	c.fontFilePath = fontFilePath
+	c.fontSize = fontSize
+	return c
+}
+
+// WithFontColor sets the sequence number label's text color, border color, border thickness (in
+// pixels), and the alpha value at which a pixel is considered part of a border rather than empty.
+func (c Config) WithFontColor(textColor, borderColor color.NRGBA, borderWidth int, borderAlphaThresh uint8) Config {
+	c.fontColor = &image.Uniform{C: textColor}
+	c.fontBorderColor = borderColor
+	c.fontBorderWidth = borderWidth
+	c.fontBorderAlphaThresh = borderAlphaThresh
+	return c
+}
+
+// WithSeqNumDigits sets the number of zero-padded digits the sequence number label is rendered
+// with (e.g. 2 pads "7" to "07").
+func (c Config) WithSeqNumDigits(digits int) Config {
+	c.numDigits = digits
+	return c
+}
+
+// WithTextLayerSize sets the dimensions of the scratch image the sequence number label is drawn
+// onto before being composited onto the thumbnail; increase this when processing very large
+// images.
+func (c Config) WithTextLayerSize(width, height int) Config {
+	c.textImgWidth = width
+	c.textImgHeight = height
+	return c
+}
+
+// WithParallelism sets how many thumbnails are rendered concurrently. 0 (the default) uses
+// runtime.NumCPU().
+func (c Config) WithParallelism(workers int) Config {
+	c.parallelism = workers
+	return c
+}
+
+// WithFormat sets the image format thumbnails are encoded as.
+func (c Config) WithFormat(format ImageFormat) Config {
+	c.format = format
+	return c
+}
+
+// WithJPEGQuality sets the JPEG quality (1-100) used when Format is FormatJPEG.
+func (c Config) WithJPEGQuality(quality int) Config {
+	c.jpegQuality = quality
+	return c
+}
+
+// WithGifOptions sets the animated GIF encoding parameters used by (*Generator).GenerateGIF:
+// per-frame delay in hundredths of a second, loop count (0 loops forever), palette size, and
+// whether to apply Floyd-Steinberg dithering when quantizing frames to the palette.
+func (c Config) WithGifOptions(frameDelay, loopCount, numColors int, dither bool) Config {
+	c.gifFrameDelay = frameDelay
+	c.gifLoopCount = loopCount
+	c.gifNumColors = numColors
+	c.gifDither = dither
+	return c
+}
+
+// WithStaticBackground configures cfg for static baseImage composition: every thumbnail in the
+// sequence shares bgImageFilePath as its background, differing only in the overlaid sequence
+// number and any TextElements.
+func (c Config) WithStaticBackground(bgImageFilePath string) Config {
+	c.static = ConfigStatic{bgImageFilePath: bgImageFilePath}
+	return c
+}
+
+// WithDynamicSource configures cfg for dynamic baseImage composition: stillSourceDirPath is
+// scanned for per-thumbnail stills named "<stillFilenamePrefix><seq>.<stillFilenameExt>", with
+// titleImageFilePath (if non-empty) overlaid on every frame.
+func (c Config) WithDynamicSource(stillSourceDirPath, stillFilenamePrefix, stillFilenameExt, titleImageFilePath string) Config {
+	c.dynamic = ConfigDynamic{
+		stillSourceDirPath:  stillSourceDirPath,
+		stillFilenamePrefix: stillFilenamePrefix,
+		stillFilenameExt:    stillFilenameExt,
+		titleImageFilePath:  titleImageFilePath,
+	}
+	return c
+}
+
+// WithGlyphCache attaches an existing GlyphCache to cfg, returning the updated copy. Callers
+// rendering several jobs with the same font, font size, color, and border settings (see
+// batch.go) can share one GlyphCache across all of them so each glyph's bordered tile is only
+// rendered once for the whole batch instead of once per Generator.
+func (c Config) WithGlyphCache(cache *GlyphCache) Config {
+	c.glyphCache = cache
+	return c
+}
+
+// WithTextElements attaches additional templated text overlays to cfg, returning the updated
+// copy. Each element is rendered on top of the baseImage/title layers and the sequence number
+// label - see TextElement for the template data and positioning options available.
+func (c Config) WithTextElements(elements []TextElement) Config {
+	c.textElements = elements
+	return c
+}
+
+// Generator holds everything needed to render a sequence of thumbnails: the loaded font, any
+// shared background/title images, and the configuration driving the run. A Generator is safe for
+// concurrent use by its own workers, but is not meant to be shared across unrelated invocations -
+// callers should construct a fresh one per run via NewGenerator.
+type Generator struct {
+	cfg           Config
+	font          *truetype.Font
+	bgImage       *image.NRGBA
+	titleImage    *image.NRGBA
+	logger        *slog.Logger
+	labelTemplate *template.Template
+	glyphCache    *GlyphCache
+	textElements  []compiledTextElement
+	templateJob   BatchJob
+	debug         bool
+
+	resultsMu sync.Mutex
+	results   []Thumbnail
+}
+
+// Thumbnail describes a single thumbnail image a Generator produced.
+type Thumbnail struct {
+	Index  int    `json:"index"`
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Label  string `json:"label"`
+}
+
+func (g *Generator) recordResult(r Thumbnail) {
+	g.resultsMu.Lock()
+	defer g.resultsMu.Unlock()
+	g.results = append(g.results, r)
+}
+
+// Results returns the Thumbnail produced by the most recent call to Generate, ordered by Index.
+func (g *Generator) Results() []Thumbnail {
+	g.resultsMu.Lock()
+	defer g.resultsMu.Unlock()
+	results := make([]Thumbnail, len(g.results))
+	copy(results, g.results)
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+	return results
+}
+
+// NewGenerator loads the font and any shared images described by cfg and returns a Generator ready
+// to have Generate called on it. The logger attached to ctx (see clibase.ContextWithLogger) is used
+// for all of the Generator's subsequent logging.
+func NewGenerator(ctx context.Context, cfg Config) (*Generator, error) {
+	fontBytes, err := os.ReadFile(cfg.fontFilePath)
+	if err != nil {
+		return nil, err
+	}
+	parsedFont, err := truetype.Parse(fontBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGenerator(clibase.LoggerFromContext(ctx), cfg, parsedFont)
+}
+
+// newGenerator builds a Generator from an already-parsed font, letting callers that render many
+// jobs against the same font file (see AddCmdGenerateBatch) parse it only once.
+func newGenerator(logger *slog.Logger, cfg Config, font *truetype.Font) (*Generator, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.format == "" {
+		cfg.format = FormatPNG
+	}
+	textElements, err := compileTextElements(cfg.textElements)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Generator{
+		cfg:          cfg,
+		font:         font,
+		logger:       logger,
+		glyphCache:   cfg.glyphCache,
+		textElements: textElements,
+		debug:        debugEnabled(),
+	}
+	if g.glyphCache == nil {
+		g.glyphCache = NewGlyphCache()
+	}
+
+	if cfg.static.bgImageFilePath != "" {
+		bgImage, err := importImg(cfg.static.bgImageFilePath)
+		if err != nil {
+			return nil, err
+		}
+		g.bgImage = bgImage
+	}
+
+	if cfg.dynamic.titleImageFilePath != "" {
+		titleImage, err := importImg(cfg.dynamic.titleImageFilePath)
+		if err != nil {
+			g.logger.Error("cannot open title image", "error", err, "title_img.path", cfg.dynamic.titleImageFilePath)
+			return nil, err
+		}
+		g.titleImage = titleImage
+	}
+
+	return g, nil
+}
+
+// Generate renders and exports the full sequence described by the Generator's Config, using a
+// worker pool sized by Config.parallelism (or runtime.NumCPU() when unset). It respects ctx
+// cancellation, stopping the dispatch of new work once ctx is done.
+func (g *Generator) Generate(ctx context.Context) error {
+	if g.bgImage != nil {
+		return g.generateStaticThumbnails(ctx)
+	}
+	return g.generateDynamicThumbnails(ctx)
+}
+
+func (g *Generator) workerCount() int {
+	if g.cfg.parallelism > 0 {
+		return g.cfg.parallelism
+	}
+	return runtime.NumCPU()
+}
+
+// runSequence fans numStart..numEnd out across a worker pool, running work for each seqNumber and
+// aggregating any errors it returns via multierror.
+func (g *Generator) runSequence(ctx context.Context, work func(seqNumber int) error) error {
+	seqCh := make(chan int)
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    error
+		workers = g.workerCount()
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seqNumber := range seqCh {
+				if err := work(seqNumber); err != nil {
+					mu.Lock()
+					errs = multierror.Append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for seqNumber := g.cfg.numStart; seqNumber <= g.cfg.numEnd; seqNumber++ {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case seqCh <- seqNumber:
+		}
+	}
+	close(seqCh)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return errs
+}
+
+// generateStaticThumbnails renders and saves thumbnails comprised of the shared background image
+// and a sequence number
+func (g *Generator) generateStaticThumbnails(ctx context.Context) error {
+	return g.runSequence(ctx, func(seqNumber int) error {
+		thumb := &thumbnail{
+			baseImage: g.bgImage,
+			seqNumber: seqNumber,
+		}
+		if err := g.renderThumbnail(thumb); err != nil {
+			return err
+		}
+		result, err := g.exportThumbnail(thumb)
+		if err != nil {
+			return err
+		}
+		g.recordResult(result)
+		return nil
+	})
+}
+
+func (g *Generator) generateDynamicThumbnails(ctx context.Context) error {
+	return g.runSequence(ctx, func(seqNumber int) error {
+		imgFilename := fmt.Sprintf("%s%d.%s", g.cfg.dynamic.stillFilenamePrefix, seqNumber, g.cfg.dynamic.stillFilenameExt)
+		imgPath := path.Join(g.cfg.dynamic.stillSourceDirPath, imgFilename)
+		img, err := importImg(imgPath)
+		if err != nil {
+			g.logger.Error("cannot open dynamic thumbnail image", "error", err, "src_img.path", imgPath, "seq_number", strconv.Itoa(seqNumber))
+			return fmt.Errorf("seq %d: cannot open dynamic thumbnail image %s: %w", seqNumber, imgPath, err)
+		}
+		thumb := &thumbnail{
+			baseImage:  img,
+			seqNumber:  seqNumber,
+			stillPath:  imgPath,
+			titleImage: g.titleImage,
+		}
+		if err := g.renderThumbnail(thumb); err != nil {
+			g.logger.Error("unable to render thumbnail image",
+				"error", err, "src_img.path", imgPath, "seq_number", strconv.Itoa(seqNumber), "title_img.path", g.cfg.dynamic.titleImageFilePath)
+			return fmt.Errorf("seq %d: unable to render thumbnail image: %w", seqNumber, err)
+		}
+		result, err := g.exportThumbnail(thumb)
+		if err != nil {
+			g.logger.Error("unable to export thumbnail image",
+				"dst.path", g.cfg.destPath, "error", err, "src_img.path", imgPath, "seq_number", strconv.Itoa(seqNumber), "title_img.path", g.cfg.dynamic.titleImageFilePath)
+			return fmt.Errorf("seq %d: unable to export thumbnail image: %w", seqNumber, err)
+		}
+		g.recordResult(result)
+		return nil
+	})
+}
+
+func addGeneratePersistentFlags(flags *pflag.FlagSet) {
+	pngFlags := &pflag.FlagSet{}
+
+	pngFlags.String(flagNameBaseName, "", "The base name for the baseImage files (required)")
+	pngFlags.String(flagNameDestPath, "", "Full path to the output destination (required)")
+	pngFlags.Uint8(fontBorderAlphaThreshold, 0, "The alpha value at which we consider a pixel to be empty/convert to a border pixel")
+	pngFlags.String(flagNameFontBorderColor, "FFFFFF", "Sequence seqNumber outline color (6 character RGB hex code)")
+	pngFlags.Int(flagNameFontBorderWidth, 2, "Sequence seqNumber outline thickness (in pixels)")
+	pngFlags.String(flagNameFontColor, "000000", "Sequence seqNumber text color (6 character RGB hex code)")
+	pngFlags.Float64(flagNameFontSize, 30, "Font size in points")
+	pngFlags.Int(flagNameParallelism, 0, "Number of thumbnails to render concurrently (defaults to the number of CPUs)")
+	pngFlags.Int(flagNameSeqNumDigits, 2, "Number of fixed places in the generated sequence seqNumber (ie. how many 0s to pad single digits with)")
+	pngFlags.Int(flagNameSeqNumPosX, 975, "X coordinate the sequence seqNumber will be drawn at")
+	pngFlags.Int(flagNameSeqNumPosY, 600, "Y coordinate the sequence seqNumber will be drawn at")
+	pngFlags.Int(flagNameSeqStart, 1, "Number to start the sequence with")
+	pngFlags.Int(flagNameSeqEnd, 10, "Number to end the sequence on")
+	pngFlags.Int(flagNameTextLayerHeight, 1080, "Height of the temporary baseImage the text is drawn onto; may need to be increased when processing very large images")
+	pngFlags.Int(flagNameTextLayerWidth, 1920, "Width of the temporary baseImage the text is drawn onto; may need to be increased when processing very large images")
+
+	flags.AddFlagSet(pngFlags)
+}
+
+func markGenerateRequiredFlags(cmd *cobra.Command) error {
+	if err := cmd.MarkPersistentFlagRequired(flagNameBaseName); err != nil {
+		return err
+	}
+	if err := cmd.MarkPersistentFlagRequired(flagNameDestPath); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addCmdGenerateFormat adds a "generate-<format>" subcommand to parentCmd, wiring its static and
+// dynamic subcommands through the shared persistent flags. extraFlags and applyExtra, when
+// non-nil, let a format register and read its own flags (e.g. JPEG's --quality) without the
+// static/dynamic RunE handlers needing to know about them.
+func addCmdGenerateFormat(parentCmd *cobra.Command, use, short string, format ImageFormat, extraFlags func(*pflag.FlagSet), applyExtra func(*pflag.FlagSet, *Config) error) {
+	formatCmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+	}
+	addGeneratePersistentFlags(formatCmd.PersistentFlags())
+	if extraFlags != nil {
+		extraFlags(formatCmd.PersistentFlags())
+	}
+	if err := markGenerateRequiredFlags(formatCmd); err != nil {
+		slog.Default().Error("unable to mark required flags", "error", err)
+		os.Exit(1)
+	}
+
+	addCmdGenerateDynamic(formatCmd, format, applyExtra)
+	addCmdGenerateStatic(formatCmd, format, applyExtra)
+
+	parentCmd.AddCommand(formatCmd)
+}
+
+// AddCmdGeneratePng adds the generate-png subcommand to a cobra.Command
+func AddCmdGeneratePng(parentCmd *cobra.Command) {
+	addCmdGenerateFormat(parentCmd, "generate-png", "generate thumbnails in PNG format", FormatPNG, nil, nil)
+}
+
+// AddCmdGenerateJpeg adds the generate-jpeg subcommand to a cobra.Command
+func AddCmdGenerateJpeg(parentCmd *cobra.Command) {
+	addCmdGenerateFormat(parentCmd, "generate-jpeg", "generate thumbnails in JPEG format", FormatJPEG,
+		func(flags *pflag.FlagSet) {
+			flags.Int(flagNameJpegQuality, jpeg.DefaultQuality, "JPEG quality (1-100)")
+		},
+		func(flags *pflag.FlagSet, cfg *Config) error {
+			quality, err := flags.GetInt(flagNameJpegQuality)
+			if err != nil {
+				return err
+			}
+			cfg.jpegQuality = quality
+			return nil
+		},
+	)
+}
+
+func addGenerateStaticFlags(flags *pflag.FlagSet) {
+	pngStaticFlags := &pflag.FlagSet{}
+	pngStaticFlags.String(flagNameBgImage, "", "Full path to the background baseImage (required)")
+
+	flags.AddFlagSet(pngStaticFlags)
+}
+
+func addCmdGenerateStatic(parentCmd *cobra.Command, format ImageFormat, applyExtra func(*pflag.FlagSet, *Config) error) {
+	staticCmd := &cobra.Command{
+		Use:   "static",
+		Short: "static baseImage composition (the only difference between thumbnails is the overlayed sequence seqNumber)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var cfg Config
+			if err := cfg.setPersistentConfigFromFlags(cmd.Flags()); err != nil {
+				return err
+			}
+			if err := cfg.setStaticConfigFromFlags(cmd.Flags()); err != nil {
+				return err
+			}
+			cfg.format = format
+			if applyExtra != nil {
+				if err := applyExtra(cmd.Flags(), &cfg); err != nil {
+					return err
+				}
+			}
+			if err := cfg.validateStatic(); err != nil {
+				return err
+			}
+
+			gen, err := NewGenerator(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+
+			// Generate aggregates per-frame errors via multierror rather than aborting, so even a
+			// run that returns an error may have successfully rendered and written most of its
+			// thumbnails; the manifest is written unconditionally before that error is returned,
+			// mirroring generate-batch, so a partial failure doesn't silently drop every other
+			// frame's manifest entry.
+			genErr := gen.Generate(cmd.Context())
+			if err := writeManifest(cmd.OutOrStdout(), clibase.OutputFormat(cmd), gen.Results()); err != nil {
+				return err
+			}
+			return genErr
+		},
+	}
+	addGenerateStaticFlags(staticCmd.Flags())
+
+	parentCmd.AddCommand(staticCmd)
+}
+
+func addGenerateDynamicFlags(flags *pflag.FlagSet) {
+	pngDynamicFlags := &pflag.FlagSet{}
+	pngDynamicFlags.String(flagNameStillFilenameExt, "still", "Filename extension on all still baseImage files")
+	pngDynamicFlags.String(flagNameStillFilenamePrefix, "E", "Filename prefix on all still baseImage files")
+	pngDynamicFlags.String(flagNameStillSrcPath, "", "Source directory containing all still baseImage files")
+	pngDynamicFlags.String(flagNameTitleOverlayPath, "", "Full path to the title overlay baseImage")
+
+	flags.AddFlagSet(pngDynamicFlags)
+}
+
+func addCmdGenerateDynamic(parentCmd *cobra.Command, format ImageFormat, applyExtra func(*pflag.FlagSet, *Config) error) {
+	dynamicCmd := &cobra.Command{
+		Use:   "dynamic",
+		Short: "dynamic baseImage composition (unique primary baseImage per thumbnail)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var cfg Config
+			if err := cfg.setPersistentConfigFromFlags(cmd.Flags()); err != nil {
+				return err
+			}
+			if err := cfg.setDynamicConfigFromFlags(cmd.Flags()); err != nil {
+				return err
+			}
+			cfg.format = format
+			if applyExtra != nil {
+				if err := applyExtra(cmd.Flags(), &cfg); err != nil {
+					return err
+				}
+			}
+			if err := cfg.validateDynamic(); err != nil {
+				return err
+			}
+
+			gen, err := NewGenerator(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+
+			// See the equivalent comment in addCmdGenerateStatic: write the manifest unconditionally
+			// before returning Generate's error, so a partial failure doesn't drop every other
+			// frame's already-written manifest entry.
+			genErr := gen.Generate(cmd.Context())
+			if err := writeManifest(cmd.OutOrStdout(), clibase.OutputFormat(cmd), gen.Results()); err != nil {
+				return err
+			}
+			return genErr
+		},
+	}
+	addGenerateDynamicFlags(dynamicCmd.Flags())
+
+	parentCmd.AddCommand(dynamicCmd)
+}
+
+func (c *Config) setPersistentConfigFromFlags(flags *pflag.FlagSet) error {
+	baseName, err := flags.GetString(flagNameBaseName)
+	if err != nil {
+		return err
+	}
+	destPath, err := flags.GetString(flagNameDestPath)
+	if err != nil {
+		return err
+	}
+	fontBorderAlphaThreshold, err := flags.GetUint8(fontBorderAlphaThreshold)
+	if err != nil {
+		return err
+	}
+	fontBorderColorStr, err := flags.GetString(flagNameFontBorderColor)
+	if err != nil {
+		return nil
+	}
+	fontBorderWidth, err := flags.GetInt(flagNameFontBorderWidth)
+	if err != nil {
+		return nil
+	}
+	fontColorStr, err := flags.GetString(flagNameFontColor)
+	if err != nil {
+		return err
+	}
+	fontSize, err := flags.GetFloat64(flagNameFontSize)
+	if err != nil {
+		return err
+	}
+	parallelism, err := flags.GetInt(flagNameParallelism)
+	if err != nil {
+		return err
+	}
+	numPlaces, err := flags.GetInt(flagNameSeqNumDigits)
+	if err != nil {
+		return err
+	}
+	numPosX, err := flags.GetInt(flagNameSeqNumPosX)
+	if err != nil {
+		return err
+	}
+	numPosY, err := flags.GetInt(flagNameSeqNumPosY)
+	if err != nil {
+		return err
+	}
+	seqStart, err := flags.GetInt(flagNameSeqStart)
+	if err != nil {
+		return err
+	}
+	seqEnd, err := flags.GetInt(flagNameSeqEnd)
+	if err != nil {
+		return err
+	}
+	textLayerHeight, err := flags.GetInt(flagNameTextLayerHeight)
+	if err != nil {
+		return err
+	}
+	textLayerWidth, err := flags.GetInt(flagNameTextLayerWidth)
+	if err != nil {
+		return err
+	}
+
+	fontBorderColor, err := imgutils.ParseHexColor(fontBorderColorStr)
+	if err != nil {
+		return err
+	}
+	fontColor, err := imgutils.ParseHexColor(fontColorStr)
+	if err != nil {
+		return err
+	}
+
+	c.baseName = baseName
+	c.destPath = destPath
+	c.fontBorderAlphaThresh = fontBorderAlphaThreshold
+	c.fontBorderColor = fontBorderColor
+	c.fontBorderWidth = fontBorderWidth
+	c.fontColor = &image.Uniform{C: fontColor}
+	c.fontFilePath = filepath.Join("assets", "fonts", "tahomabd.ttf")
+	c.fontSize = fontSize
+	c.parallelism = parallelism
+	c.numDigits = numPlaces
+	c.numPosX = numPosX
+	c.numPosY = numPosY
+	c.numEnd = seqEnd
+	c.numStart = seqStart
+	c.textImgHeight = textLayerHeight
+	c.textImgWidth = textLayerWidth
+
+	return nil
+}
+
+func (c *Config) setStaticConfigFromFlags(flags *pflag.FlagSet) error {
+	bgImage, err := flags.GetString(flagNameBgImage)
+	if err != nil {
+		return err
+	}
+
+	c.static.bgImageFilePath = bgImage
+
+	return nil
+}
+
+func (c *Config) setDynamicConfigFromFlags(flags *pflag.FlagSet) error {
+	stillFileDirPath, err := flags.GetString(flagNameStillSrcPath)
+	if err != nil {
+		return err
+	}
+	stillFileExt, err := flags.GetString(flagNameStillFilenameExt)
+	if err != nil {
+		return err
+	}
+	stillFilePrefix, err := flags.GetString(flagNameStillFilenamePrefix)
+	if err != nil {
+		return err
+	}
+	titleImgFilePath, err := flags.GetString(flagNameTitleOverlayPath)
+	if err != nil {
+		return err
+	}
+
+	c.dynamic.stillSourceDirPath = stillFileDirPath
+	c.dynamic.stillFilenameExt = stillFileExt
+	c.dynamic.stillFilenamePrefix = stillFilePrefix
+	c.dynamic.titleImageFilePath = titleImgFilePath
+
+	return nil
+}
+
+func (c *Config) validate() error {
+	var result error
+
+	if c.numStart < 0 || c.numEnd <= 0 {
+		result = multierror.Append(result, fmt.Errorf("invalid sequence: start must be a positive seqNumber"))
+	}
+	if c.numStart >= c.numEnd {
+		result = multierror.Append(result, fmt.Errorf("invalid sequence: start seqNumber is the same as or after the end seqNumber"))
+	}
+	if c.numDigits < 0 {
+		result = multierror.Append(result, fmt.Errorf("invalid numFixedPlaces: must be a positive seqNumber"))
+	}
+
+	return result
+}
+
+func (c *Config) validateStatic() error {
+	result := c.validate()
+
+	if c.static.bgImageFilePath == "" {
+		result = multierror.Append(result, fmt.Errorf("no background baseImage file path specified"))
+	}
+
+	return result
+}
+
+func (c *Config) validateDynamic() error {
+	result := c.validate()
+
+	if c.dynamic.titleImageFilePath == "" {
+		result = multierror.Append(result, fmt.Errorf("no title overlay baseImage file path specified"))
+	}
+
+	return result
+}
+
+// importImg decodes the image at fpath. A decoder registered for fpath's extension (see
+// RegisterDecoder) is consulted first; if none is registered, it falls back to the standard
+// image.Decode, which covers any format blank-imported for its side-effecting registration (see
+// decoder.go).
+func importImg(fpath string) (nrgba *image.NRGBA, err error) {
+	fileData, err := os.Open(fpath)
+	if err != nil {
+		return
+	}
+	defer fileData.Close()
+
+	var imageData image.Image
+	if decoder, ok := lookupDecoder(filepath.Ext(fpath)); ok {
+		imageData, err = decoder(fileData)
+	} else {
+		imageData, _, err = image.Decode(fileData)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode %s (supported formats: %s): %w", fpath, strings.Join(knownDecoderFormats, ", "), err)
+	}
+
+	nrgba = image.NewNRGBA(imageData.Bounds())
+	draw.Draw(nrgba, nrgba.Bounds(), imageData, image.Point{}, draw.Src)
+
+	return
+}
+
+func (thumb *thumbnail) setPaddedNumberFromNumber(logger *slog.Logger, numDigits int) {
+	raw := strconv.Itoa(thumb.seqNumber)
+	rawCharCount := strings.Count(raw, "") - 1
+
+	if numDigits <= 1 || rawCharCount >= numDigits {
+		// No padding required (seqNumber is longer than or equal to the seqNumber of places, so no leading 0s needed)
+		thumb.paddedSeqNumber = raw
+	}
+
+	paddedNum := raw
+	for i := 1; i <= numDigits-rawCharCount; i++ {
+		paddedNum = fmt.Sprintf("0%s", paddedNum)
+	}
+
+	logger.Debug("setPaddedNumberFromNumber result",
+		"numDigits", numDigits, "thumb.seqNumber", thumb.seqNumber, "thumb.seqNumber.padded", paddedNum, "thumb.seqNumber.raw_char_count", rawCharCount)
+	thumb.paddedSeqNumber = paddedNum
+}
+
+// labelTemplateData is the data made available to a Generator's labelTemplate, if set.
+type labelTemplateData struct {
+	Index  int
+	Padded string
+	Total  int
+	Job    BatchJob
+}
+
+// thumbnailLabel returns the text to draw onto thumb, and the text recorded as its Thumbnail.Label.
+// When the Generator has no labelTemplate (the common case), it falls back to the original "#NNN"
+// format.
+func (g *Generator) thumbnailLabel(thumb *thumbnail) (string, error) {
+	if g.labelTemplate == nil {
+		return fmt.Sprintf("#%s", thumb.paddedSeqNumber), nil
+	}
+
+	var buf strings.Builder
+	data := labelTemplateData{
+		Index:  thumb.seqNumber,
+		Padded: thumb.paddedSeqNumber,
+		Total:  g.cfg.numEnd - g.cfg.numStart + 1,
+		Job:    g.templateJob,
+	}
+	if err := g.labelTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("unable to render label template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// renderThumbnail creates the baseImage and the seqNumber overlay for the thumbnail
+func (g *Generator) renderThumbnail(thumb *thumbnail) error {
+	cfg := g.cfg
+
+	// generate padded seqNumber string
+	thumb.setPaddedNumberFromNumber(g.logger, cfg.numDigits)
+
+	// create a new, blank baseImage
+	thumb.image = image.NewNRGBA(thumb.baseImage.Bounds())
+
+	// draw the baseImage onto the blank
+	draw.Draw(thumb.image, thumb.image.Bounds(), thumb.baseImage, image.Point{}, draw.Src)
+
+	// if there is a title image, add that layer next
+	if thumb.titleImage != nil {
+		g.logger.Debug("adding title layer")
+		draw.Draw(thumb.image, thumb.image.Bounds(), thumb.titleImage, image.Point{}, draw.Over)
+	}
+
+	// create a temp image to draw the text onto
+	textImg := image.NewNRGBA(image.Rect(0, 0, cfg.textImgWidth, cfg.textImgHeight))
+
+	// calc Y level to place the drawing dot at, given the font size and DPI
+	// (the dot for drawing a char starts at the _bottom_ left of the char, so we need enough Y space to fit the char height)
+	y := int(math.Ceil(cfg.fontSize * fontDPI / 72))
+	startDot := fixed.Point26_6{
+		X: fixed.I(0 + 2 + cfg.fontBorderWidth),
+		Y: fixed.I(y + ((2 + cfg.fontBorderWidth) * 2)),
+	}
+
+	text, err := g.thumbnailLabel(thumb)
+	if err != nil {
+		return err
+	}
+	// compose the label from the Generator's GlyphCache - each rune's bordered tile is only
+	// rendered once per (rune, font, fontSize, dpi, hinting), not once per thumbnail
+	if err := g.drawLabel(textImg, text, startDot); err != nil {
+		return err
+	}
+	if g.debug {
+		fileName := fmt.Sprintf("thumbnail_%s_%s_debug_textlayer.png", cfg.baseName, thumb.paddedSeqNumber)
+		filePath := filepath.Join(cfg.destPath, fileName)
+		if _, err := encodeImage(g.logger, textImg, filePath, FormatPNG, EncodeOptions{}); err != nil {
+			return err
+		}
+	}
+
+	textRect := imgutils.OccupiedAreaRect(textImg)
+	textRectAbs := image.Rectangle{
+		Min: image.Point{X: 0, Y: 0},
+		Max: textRect.Size(),
+	}
+
+	// manual placement
+	// destRect := textRectAbs.Bounds().Add(baseImage.Point{X: cfg.numPosX, Y:cfg.numPosY})
+
+	// auto lower right corner
+	// calcX := thumb.baseImage.Bounds().Dx() - textRectAbs.Bounds().Dx() - 25
+	// calcY := thumb.baseImage.Bounds().Dy() - textRectAbs.Bounds().Dy() - 25
+
+	// auto upper right corner
+	calcX := thumb.image.Bounds().Dx() - textRectAbs.Bounds().Dx() - 25
+	calcY := textRectAbs.Bounds().Dy() - 80
+	destRect := textRectAbs.Bounds().Add(image.Point{X: calcX, Y: calcY})
+
+	draw.Draw(thumb.image, destRect, textImg, textRect.Min, draw.Over)
+
+	for _, el := range g.textElements {
+		if err := el.render(g, thumb.image, thumb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RenderOne renders a single thumbnail for seqNumber using src as the primary image - the
+// background for a static Generator, or a per-sequence still for a dynamic one - without touching
+// disk anywhere. It lets a caller embedding this package drive rendering straight from an
+// in-memory image (e.g. one decoded from an upload or pulled from object storage) instead of going
+// through Generate, which always reads its images from the paths on Config.
+func (g *Generator) RenderOne(seq int, src image.Image) (*image.NRGBA, error) {
+	baseImage, ok := src.(*image.NRGBA)
+	if !ok {
+		baseImage = image.NewNRGBA(src.Bounds())
+		draw.Draw(baseImage, baseImage.Bounds(), src, image.Point{}, draw.Src)
+	}
+
+	thumb := &thumbnail{
+		baseImage:  baseImage,
+		seqNumber:  seq,
+		titleImage: g.titleImage,
+	}
+	if err := g.renderThumbnail(thumb); err != nil {
+		return nil, err
+	}
+	return scaleToFinal(thumb.image), nil
+}
+
+// scaleToFinal scales img down to fit within imageFinalWidth x imageFinalHeight, if it's larger
+// than that in either dimension, returning it unchanged otherwise.
+func scaleToFinal(img *image.NRGBA) *image.NRGBA {
+	if img.Rect.Max.X <= imageFinalWidth && img.Rect.Max.Y <= imageFinalHeight {
+		return img
+	}
+	scaledImage := image.NewNRGBA(image.Rect(0, 0, imageFinalWidth, imageFinalHeight))
+	draw.CatmullRom.Scale(scaledImage, scaledImage.Rect, img, img.Bounds(), draw.Over, nil)
+	return scaledImage
+}
+
+func (g *Generator) exportThumbnail(thumb *thumbnail) (Thumbnail, error) {
+	g.logger.Debug("exporting image", "height", thumb.image.Rect.Max.Y, "width", thumb.image.Rect.Max.X)
+	if thumb.image.Rect.Max.X > imageFinalWidth || thumb.image.Rect.Max.Y > imageFinalHeight {
+		g.logger.Debug("scaling image", "target.height", imageFinalHeight, "target.width", imageFinalWidth)
+	}
+	thumb.image = scaleToFinal(thumb.image)
+	fileName := fmt.Sprintf("thumbnail_%s_%s.%s", g.cfg.baseName, thumb.paddedSeqNumber, g.cfg.format.extension())
+	filePath := filepath.Join(g.cfg.destPath, fileName)
+	sum, err := encodeImage(g.logger, thumb.image, filePath, g.cfg.format, EncodeOptions{JPEGQuality: g.cfg.jpegQuality})
+	if err != nil {
+		return Thumbnail{}, err
+	}
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return Thumbnail{}, err
+	}
+	label, err := g.thumbnailLabel(thumb)
+	if err != nil {
+		return Thumbnail{}, err
+	}
+
+	return Thumbnail{
+		Index:  thumb.seqNumber,
+		Path:   absPath,
+		SHA256: sum,
+		Width:  thumb.image.Rect.Dx(),
+		Height: thumb.image.Rect.Dy(),
+		Label:  label,
+	}, nil
+}
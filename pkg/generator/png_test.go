@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"context"
 	"image"
 	"image/color"
 	"path/filepath"
@@ -10,14 +11,13 @@ import (
 )
 
 func BenchmarkGenerateThumbnails(b *testing.B) {
-	config := Config{
+	cfg := Config{
 		baseName:              "benchmark",
-		bgImageFilePath:       filepath.Join("testdata", "images", "bgimage.png"),
 		destPath:              filepath.Join("testdata", "output"),
 		fontBorderAlphaThresh: 250,
-		fontBorderColor:       color.NRGBA{R:255, G:255, B:255, A:255},
+		fontBorderColor:       color.NRGBA{R: 255, G: 255, B: 255, A: 255},
 		fontBorderWidth:       3,
-		fontColor:             &image.Uniform{C: color.NRGBA{R:0, G:0, B:0, A:255}},
+		fontColor:             &image.Uniform{C: color.NRGBA{R: 0, G: 0, B: 0, A: 255}},
 		fontFilePath:          filepath.Join("testdata", "fonts", "tahomabd.ttf"),
 		fontSize:              25,
 		numDigits:             3,
@@ -27,12 +27,15 @@ func BenchmarkGenerateThumbnails(b *testing.B) {
 		numStart:              1,
 		textImgHeight:         1080,
 		textImgWidth:          1920,
+		static: ConfigStatic{
+			bgImageFilePath: filepath.Join("testdata", "images", "bgimage.png"),
+		},
 	}
-	setConf(config)
-	err := configFreetype(config.fontFilePath)
-	assert.NoError(b, err)
-	err = importBackground(config.bgImageFilePath)
-	assert.NoError(b, err)
-	err = generateThumbnails()
+
+	gen, err := NewGenerator(context.Background(), cfg)
 	assert.NoError(b, err)
-}
\ No newline at end of file
+
+	for i := 0; i < b.N; i++ {
+		assert.NoError(b, gen.Generate(context.Background()))
+	}
+}
@@ -0,0 +1,236 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/SkyMack/imgutils"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Anchor identifies which point of a rendered TextElement's block its X/Y coordinates describe.
+type Anchor int
+
+const (
+	AnchorTopLeft Anchor = iota
+	AnchorTopCenter
+	AnchorTopRight
+	AnchorCenterLeft
+	AnchorCenter
+	AnchorCenterRight
+	AnchorBottomLeft
+	AnchorBottomCenter
+	AnchorBottomRight
+)
+
+// TextElement describes one block of templated text to overlay onto every thumbnail, in addition
+// to the sequence number label. Template may reference {{.Seq}}, {{.PaddedSeq}}, {{.BaseName}},
+// {{.StillPath}}, and {{.Env.NAME}}. Text wider than MaxWidth (if set) is greedily word-wrapped
+// onto additional lines.
+type TextElement struct {
+	Template    string
+	X, Y        int
+	Anchor      Anchor
+	FontSize    float64
+	MaxWidth    int
+	Color       color.NRGBA
+	BorderColor color.NRGBA
+	BorderWidth int
+}
+
+// textElementData is made available to a TextElement's Template.
+type textElementData struct {
+	Seq       int
+	PaddedSeq string
+	BaseName  string
+	StillPath string
+	Env       map[string]string
+}
+
+// compiledTextElement is a TextElement with its Template parsed once up front, so a malformed
+// template fails at Generator construction rather than partway through a run.
+type compiledTextElement struct {
+	TextElement
+	tmpl *template.Template
+}
+
+// compileTextElements parses every element's Template.
+func compileTextElements(elements []TextElement) ([]compiledTextElement, error) {
+	compiled := make([]compiledTextElement, 0, len(elements))
+	for i, el := range elements {
+		tmpl, err := template.New(fmt.Sprintf("text-element-%d", i)).Parse(el.Template)
+		if err != nil {
+			return nil, fmt.Errorf("text element %d: %w", i, err)
+		}
+		compiled = append(compiled, compiledTextElement{TextElement: el, tmpl: tmpl})
+	}
+	return compiled, nil
+}
+
+// render draws el onto dst for thumb: its Template is executed, greedily word-wrapped to fit
+// MaxWidth (when set), and the resulting block is anchored at (el.X, el.Y) per el.Anchor, using
+// the font's actual metrics (ascent and line height) rather than hard-coded pixel offsets.
+func (el compiledTextElement) render(g *Generator, dst *image.NRGBA, thumb *thumbnail) error {
+	var buf bytes.Buffer
+	data := textElementData{
+		Seq:       thumb.seqNumber,
+		PaddedSeq: thumb.paddedSeqNumber,
+		BaseName:  g.cfg.baseName,
+		StillPath: thumb.stillPath,
+		Env:       envMap(),
+	}
+	if err := el.tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("text element: %w", err)
+	}
+	text := buf.String()
+	if text == "" {
+		return nil
+	}
+
+	face := truetype.NewFace(g.font, &truetype.Options{Size: el.FontSize, DPI: fontDPI, Hinting: font.HintingFull})
+	metrics := face.Metrics()
+	lines := wrapText(face, text, el.MaxWidth)
+
+	lineHeight := metrics.Height.Ceil()
+	blockHeight := lineHeight * len(lines)
+	blockWidth := 0
+	for _, line := range lines {
+		if w := font.MeasureString(face, line).Ceil(); w > blockWidth {
+			blockWidth = w
+		}
+	}
+
+	pad := el.BorderWidth + 4
+	canvas := image.NewNRGBA(image.Rect(0, 0, blockWidth+pad*2, blockHeight+pad*2))
+	drawer := &font.Drawer{Dst: canvas, Src: &image.Uniform{C: el.Color}, Face: face}
+	for i, line := range lines {
+		lineWidth := font.MeasureString(face, line).Ceil()
+		lineX := pad
+		switch horizontalAnchor(el.Anchor) {
+		case anchorHCenter:
+			lineX = pad + (blockWidth-lineWidth)/2
+		case anchorHRight:
+			lineX = pad + (blockWidth - lineWidth)
+		}
+		baselineY := pad + metrics.Ascent.Ceil() + i*lineHeight
+		drawer.Dot = fixed.Point26_6{X: fixed.I(lineX), Y: fixed.I(baselineY)}
+		drawer.DrawString(line)
+	}
+	if el.BorderWidth > 0 {
+		imgutils.AddBorders(canvas, el.BorderColor, el.BorderWidth, 0)
+	}
+
+	destX, destY := anchorOrigin(el.Anchor, el.X, el.Y, canvas.Bounds().Dx(), canvas.Bounds().Dy())
+	destRect := image.Rect(destX, destY, destX+canvas.Bounds().Dx(), destY+canvas.Bounds().Dy())
+	draw.Draw(dst, destRect, canvas, image.Point{}, draw.Over)
+	return nil
+}
+
+// wrapText greedily breaks text into lines no wider than maxWidth (when positive), measuring
+// each candidate line with face's actual glyph advances. A maxWidth of 0 disables wrapping, only
+// splitting on explicit newlines.
+func wrapText(face font.Face, text string, maxWidth int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		if maxWidth <= 0 {
+			lines = append(lines, paragraph)
+			continue
+		}
+
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := words[0]
+		for _, word := range words[1:] {
+			candidate := line + " " + word
+			if font.MeasureString(face, candidate).Ceil() > maxWidth {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+type horizontalAlign int
+
+const (
+	anchorHLeft horizontalAlign = iota
+	anchorHCenter
+	anchorHRight
+)
+
+type verticalAlign int
+
+const (
+	anchorVTop verticalAlign = iota
+	anchorVCenter
+	anchorVBottom
+)
+
+func horizontalAnchor(a Anchor) horizontalAlign {
+	switch a {
+	case AnchorTopCenter, AnchorCenter, AnchorBottomCenter:
+		return anchorHCenter
+	case AnchorTopRight, AnchorCenterRight, AnchorBottomRight:
+		return anchorHRight
+	default:
+		return anchorHLeft
+	}
+}
+
+func verticalAnchor(a Anchor) verticalAlign {
+	switch a {
+	case AnchorCenterLeft, AnchorCenter, AnchorCenterRight:
+		return anchorVCenter
+	case AnchorBottomLeft, AnchorBottomCenter, AnchorBottomRight:
+		return anchorVBottom
+	default:
+		return anchorVTop
+	}
+}
+
+// anchorOrigin converts an anchor point (x, y) plus the anchor itself into the top-left corner a
+// w x h block should be drawn at.
+func anchorOrigin(a Anchor, x, y, w, h int) (int, int) {
+	originX := x
+	switch horizontalAnchor(a) {
+	case anchorHCenter:
+		originX = x - w/2
+	case anchorHRight:
+		originX = x - w
+	}
+
+	originY := y
+	switch verticalAnchor(a) {
+	case anchorVCenter:
+		originY = y - h/2
+	case anchorVBottom:
+		originY = y - h
+	}
+	return originX, originY
+}
+
+func envMap() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	return env
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font"
+)
+
+// loadTestFace parses testdata/fonts/test.ttf for tests that need to measure real glyph advances.
+func loadTestFace(t *testing.T) font.Face {
+	t.Helper()
+	fontBytes, err := os.ReadFile(filepath.Join("testdata", "fonts", "test.ttf"))
+	require.NoError(t, err)
+	parsedFont, err := truetype.Parse(fontBytes)
+	require.NoError(t, err)
+	return truetype.NewFace(parsedFont, &truetype.Options{Size: 30, DPI: fontDPI, Hinting: font.HintingFull})
+}
+
+func TestWrapTextNoMaxWidthOnlySplitsOnNewlines(t *testing.T) {
+	face := loadTestFace(t)
+	lines := wrapText(face, "one two three\nfour five six", 0)
+	assert.Equal(t, []string{"one two three", "four five six"}, lines)
+}
+
+func TestWrapTextGreedyWrap(t *testing.T) {
+	face := loadTestFace(t)
+	word := font.MeasureString(face, "word").Ceil()
+
+	lines := wrapText(face, "word word word word", word*2)
+
+	require.True(t, len(lines) > 1, "text wider than maxWidth should wrap onto more than one line")
+	for _, line := range lines {
+		assert.LessOrEqual(t, font.MeasureString(face, line).Ceil(), word*2,
+			"no wrapped line should measure wider than maxWidth")
+	}
+	assert.Equal(t, "word word word word", strings.Join(lines, " "),
+		"wrapping should only break lines, never drop or reorder a word")
+}
+
+func TestWrapTextEmptyParagraphPreservesBlankLine(t *testing.T) {
+	face := loadTestFace(t)
+	lines := wrapText(face, "one\n\nthree", 1000)
+	assert.Equal(t, []string{"one", "", "three"}, lines)
+}
+
+func TestHorizontalAnchor(t *testing.T) {
+	tests := []struct {
+		anchor Anchor
+		want   horizontalAlign
+	}{
+		{AnchorTopLeft, anchorHLeft},
+		{AnchorCenterLeft, anchorHLeft},
+		{AnchorBottomLeft, anchorHLeft},
+		{AnchorTopCenter, anchorHCenter},
+		{AnchorCenter, anchorHCenter},
+		{AnchorBottomCenter, anchorHCenter},
+		{AnchorTopRight, anchorHRight},
+		{AnchorCenterRight, anchorHRight},
+		{AnchorBottomRight, anchorHRight},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, horizontalAnchor(tt.anchor))
+	}
+}
+
+func TestVerticalAnchor(t *testing.T) {
+	tests := []struct {
+		anchor Anchor
+		want   verticalAlign
+	}{
+		{AnchorTopLeft, anchorVTop},
+		{AnchorTopCenter, anchorVTop},
+		{AnchorTopRight, anchorVTop},
+		{AnchorCenterLeft, anchorVCenter},
+		{AnchorCenter, anchorVCenter},
+		{AnchorCenterRight, anchorVCenter},
+		{AnchorBottomLeft, anchorVBottom},
+		{AnchorBottomCenter, anchorVBottom},
+		{AnchorBottomRight, anchorVBottom},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, verticalAnchor(tt.anchor))
+	}
+}
+
+func TestAnchorOrigin(t *testing.T) {
+	tests := []struct {
+		name       string
+		anchor     Anchor
+		x, y, w, h int
+		wantX      int
+		wantY      int
+	}{
+		{"top left is untouched", AnchorTopLeft, 100, 200, 40, 20, 100, 200},
+		{"top center offsets x by half width", AnchorTopCenter, 100, 200, 40, 20, 80, 200},
+		{"top right offsets x by the full width", AnchorTopRight, 100, 200, 40, 20, 60, 200},
+		{"center left offsets y by half height", AnchorCenterLeft, 100, 200, 40, 20, 100, 190},
+		{"center offsets both by half", AnchorCenter, 100, 200, 40, 20, 80, 190},
+		{"bottom left offsets y by the full height", AnchorBottomLeft, 100, 200, 40, 20, 100, 180},
+		{"bottom right offsets both by the full dimension", AnchorBottomRight, 100, 200, 40, 20, 60, 180},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotX, gotY := anchorOrigin(tt.anchor, tt.x, tt.y, tt.w, tt.h)
+			assert.Equal(t, tt.wantX, gotX, "x")
+			assert.Equal(t, tt.wantY, gotY, "y")
+		})
+	}
+}